@@ -0,0 +1,60 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"github.com/mendersoftware/deployments/docs"
+)
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>Deployments API docs</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@3/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@3/swagger-ui-bundle.js"></script>
+  <script>
+    window.onload = function() {
+      SwaggerUIBundle({
+        url: "openapi.yaml",
+        dom_id: "#swagger-ui"
+      });
+    };
+  </script>
+</body>
+</html>
+`
+
+// ServeOpenAPISpec serves the OpenAPI 3.0 spec generated by `make swagger`
+// from the @-annotations on this package's handlers. It is deliberately not
+// itself @Router-annotated: the spec describes the API it documents, not
+// the meta endpoints that serve it.
+func (d *DeploymentsApiHandlers) ServeOpenAPISpec(w rest.ResponseWriter, r *rest.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.(http.ResponseWriter).Write([]byte(docs.OpenAPISpec))
+}
+
+// ServeDocs serves a Swagger UI page rendering the OpenAPI spec served at
+// ServeOpenAPISpec.
+func (d *DeploymentsApiHandlers) ServeDocs(w rest.ResponseWriter, r *rest.Request) {
+	w.Header().Set("Content-Type", "text/html")
+	_, _ = w.(http.ResponseWriter).Write([]byte(swaggerUIPage))
+}
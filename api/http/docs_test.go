@@ -0,0 +1,50 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/ant0ine/go-json-rest/rest/test"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/deployments/docs"
+)
+
+func TestGetOpenAPISpec(t *testing.T) {
+	d := &DeploymentsApiHandlers{}
+	api := setUpRestTest("/api/0.0.1/openapi.yaml", rest.Get, d.ServeOpenAPISpec)
+
+	req, err := http.NewRequest(http.MethodGet, "http://localhost/api/0.0.1/openapi.yaml", nil)
+	assert.NoError(t, err)
+	recorded := test.RunRequest(t, api.MakeHandler(), req)
+	recorded.CodeIs(http.StatusOK)
+	recorded.HeaderIs("Content-Type", "application/yaml")
+	assert.Equal(t, docs.OpenAPISpec, recorded.Recorder.Body.String())
+}
+
+func TestGetDocs(t *testing.T) {
+	d := &DeploymentsApiHandlers{}
+	api := setUpRestTest("/api/0.0.1/docs", rest.Get, d.ServeDocs)
+
+	req, err := http.NewRequest(http.MethodGet, "http://localhost/api/0.0.1/docs", nil)
+	assert.NoError(t, err)
+	recorded := test.RunRequest(t, api.MakeHandler(), req)
+	recorded.CodeIs(http.StatusOK)
+	recorded.HeaderIs("Content-Type", "text/html")
+	assert.Contains(t, recorded.Recorder.Body.String(), "swagger-ui")
+}
@@ -0,0 +1,33 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"github.com/ant0ine/go-json-rest/rest"
+)
+
+// setUpRestTest builds a minimal rest.Api serving a single route, for use
+// by handler-level tests in this package.
+func setUpRestTest(route string, routeType func(string, rest.HandlerFunc) *rest.Route, handler rest.HandlerFunc) *rest.Api {
+	router, err := rest.MakeRouter(routeType(route, handler))
+	if err != nil {
+		panic(err)
+	}
+
+	api := rest.NewApi()
+	api.SetApp(router)
+
+	return api
+}
@@ -0,0 +1,344 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package http implements the deployments service's REST API.
+package http
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/deployments/app"
+	"github.com/mendersoftware/deployments/app/artifacttype"
+	"github.com/mendersoftware/deployments/model"
+	"github.com/mendersoftware/deployments/store"
+	"github.com/mendersoftware/deployments/utils/restutil/view"
+)
+
+const (
+	formFieldName                  = "name"
+	formFieldDescription           = "description"
+	formFieldSize                  = "size"
+	formFieldDeviceTypesCompatible = "device_types_compatible"
+	formFieldType                  = "type"
+	formFieldArgs                  = "args"
+)
+
+// DeploymentsApiHandlers wires the app and store layers into the REST API
+// surface.
+type DeploymentsApiHandlers struct {
+	store       store.DataStore
+	view        *view.RESTView
+	app         app.App
+	constraints *model.ArtifactUploadConstraints
+	registry    *artifacttype.Registry
+}
+
+// NewDeploymentsApiHandlers creates a DeploymentsApiHandlers backed by the
+// given store, view and app layer. constraints and registry may both be
+// nil, in which case only structural validation (required fields) is
+// enforced and every "type" value is accepted.
+func NewDeploymentsApiHandlers(
+	store store.DataStore,
+	view *view.RESTView,
+	app app.App,
+	constraints *model.ArtifactUploadConstraints,
+	registry *artifacttype.Registry,
+) *DeploymentsApiHandlers {
+	return &DeploymentsApiHandlers{
+		store:       store,
+		view:        view,
+		app:         app,
+		constraints: constraints,
+		registry:    registry,
+	}
+}
+
+// GenerateImage accepts a multipart/form-data artifact upload, parses its
+// metadata and stream, and hands it to the app layer for storage.
+//
+// @Summary Upload an artifact
+// @Description Accepts an artifact as a single multipart/form-data request and stores it.
+// @Tags artifacts
+// @Accept multipart/form-data
+// @Produce json
+// @Param name formData string true "Artifact name, unique across all artifacts"
+// @Param description formData string false "Artifact description"
+// @Param size formData integer true "Artifact size in bytes, must match the file part"
+// @Param device_types_compatible formData string false "Comma-separated list of compatible device types"
+// @Param type formData string false "Artifact type, must be registered in the artifacttype registry"
+// @Param args formData string false "Type-specific build arguments, opaque to this endpoint"
+// @Success 201 "Created; Location header points at the new artifact"
+// @Failure 400 {object} view.ValidationErrorResponse "Malformed request or constraint violation"
+// @Failure 422 {object} view.ValidationErrorResponse "Artifact name is not unique or media processing failed"
+// @Failure 500 {object} view.ErrorResponse "Internal error"
+// @Router /artifacts/generate [post]
+func (d *DeploymentsApiHandlers) GenerateImage(w rest.ResponseWriter, r *rest.Request) {
+	multipartUploadMsg, fields, err := ParseMultipart(r.Request, d.constraints)
+	if err != nil {
+		d.view.RenderError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if len(fields) > 0 {
+		d.view.RenderValidationError(w, r, fields, http.StatusBadRequest)
+		return
+	}
+
+	if d.registry != nil {
+		processor, ok := d.registry.Get(multipartUploadMsg.Type)
+		if !ok {
+			d.view.RenderUnsupportedType(w, r, d.registry.Types())
+			return
+		}
+		if err := dispatchArtifactType(r.Context(), processor, multipartUploadMsg); err != nil {
+			d.view.RenderError(w, r, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	imgID, err := d.app.GenerateImage(r.Context(), multipartUploadMsg)
+	if err != nil {
+		d.renderGenerateImageError(w, r, err)
+		return
+	}
+	d.view.RenderSuccessPost(w, r, imgID)
+}
+
+// renderGenerateImageError maps an error returned by app.GenerateImage to a
+// response. It is shared by every upload path (the single-shot multipart
+// endpoint and the tus.io resumable endpoint) so the same underlying
+// failure gets the same status code regardless of which one hit it.
+func (d *DeploymentsApiHandlers) renderGenerateImageError(w rest.ResponseWriter, r *rest.Request, err error) {
+	switch errors.Cause(err) {
+	case app.ErrModelArtifactNotUnique:
+		d.view.RenderValidationError(w, r, []model.FieldError{
+			{Name: "name", Code: "not_unique", Message: err.Error()},
+		}, http.StatusUnprocessableEntity)
+	case app.ErrModelArtifactFileTooLarge:
+		d.view.RenderValidationError(w, r, []model.FieldError{
+			{Name: "size", Code: "too_large", Message: err.Error()},
+		}, http.StatusBadRequest)
+	case app.ErrModelParsingArtifactFailed:
+		d.view.RenderError(w, r, err, http.StatusBadRequest)
+	case app.ErrModelMediaTooLarge, app.ErrModelMediaUnsupportedCodec:
+		d.view.RenderError(w, r, err, http.StatusBadRequest)
+	case app.ErrModelMediaProcessingFailed:
+		d.view.RenderError(w, r, err, http.StatusUnprocessableEntity)
+	default:
+		d.view.RenderInternalError(w, r, err)
+	}
+}
+
+// dispatchArtifactType runs the processor registered for msg.Type against
+// the upload, so a type's actual parsing/build logic - not just its name -
+// gates whether the upload is accepted. An uploaded payload is handed to
+// Parse; a processor with no payload of its own (e.g. docker_image, built
+// from a registry reference) has its artifact stream assembled by Build
+// instead. Metadata Parse extracts from the payload itself (e.g. the chart
+// name out of a helm_chart's Chart.yaml) overrides the client-supplied
+// form fields, since the content is authoritative over what the client
+// claims about it.
+func dispatchArtifactType(ctx context.Context, processor artifacttype.Processor, msg *model.MultipartGenerateImageMsg) error {
+	if msg.ArtifactReader == nil {
+		built, err := processor.Build(ctx, artifacttype.BuildArgs{
+			Meta: msg.MetaConstructor,
+			Args: msg.Args,
+		})
+		if err != nil {
+			return err
+		}
+		msg.ArtifactReader = built
+		return nil
+	}
+
+	meta, err := processor.Parse(ctx, artifacttype.ParseArgs{
+		Reader: msg.ArtifactReader,
+		Args:   msg.Args,
+	})
+	if err != nil {
+		return err
+	}
+	applyArtifactMetadata(msg, meta)
+	return nil
+}
+
+// applyArtifactMetadata overrides the fields of msg.MetaConstructor that
+// meta actually sets, leaving the client-supplied value wherever the
+// processor didn't extract one.
+func applyArtifactMetadata(msg *model.MultipartGenerateImageMsg, meta *model.ArtifactMetadata) {
+	if meta == nil {
+		return
+	}
+	if meta.Name != "" {
+		msg.MetaConstructor.Name = meta.Name
+	}
+	if meta.Size != 0 {
+		msg.ArtifactSize = meta.Size
+	}
+	if len(meta.DeviceTypesCompatible) > 0 {
+		msg.MetaConstructor.DeviceTypesCompatible = meta.DeviceTypesCompatible
+	}
+}
+
+// ParseMultipart reads the multipart/form-data body of an artifact upload
+// request into a MultipartGenerateImageMsg. Structural problems with the
+// request itself (not a multipart body at all, no boundary, I/O errors) are
+// returned as err; every model.ArtifactUploadConstraints violation found in
+// an otherwise well-formed body is instead collected and returned together
+// as fields, so callers can report them all in a single response.
+func ParseMultipart(r *http.Request, constraints *model.ArtifactUploadConstraints) (*model.MultipartGenerateImageMsg, []model.FieldError, error) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader := multipart.NewReader(r.Body, params["boundary"])
+
+	msg := &model.MultipartGenerateImageMsg{
+		MetaConstructor: &model.SoftwareImageMetaConstructor{},
+	}
+
+	var fields []model.FieldError
+	sawFilePart := false
+
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+
+		switch part.FormName() {
+		case formFieldName:
+			value, _ := ioutil.ReadAll(part)
+			msg.MetaConstructor.Name = string(value)
+		case formFieldDescription:
+			value, _ := ioutil.ReadAll(part)
+			msg.MetaConstructor.Description = string(value)
+		case formFieldSize:
+			value, _ := ioutil.ReadAll(part)
+			msg.ArtifactSize, _ = strconv.ParseInt(string(value), 10, 64)
+		case formFieldDeviceTypesCompatible:
+			value, _ := ioutil.ReadAll(part)
+			msg.MetaConstructor.DeviceTypesCompatible = strings.Split(string(value), ",")
+		case formFieldType:
+			value, _ := ioutil.ReadAll(part)
+			msg.Type = string(value)
+		case formFieldArgs:
+			value, _ := ioutil.ReadAll(part)
+			msg.Args = string(value)
+		default:
+			sawFilePart = true
+			if part.Header.Get("Content-Type") == "" {
+				fields = append(fields, model.FieldError{
+					Name:    "file",
+					Code:    "invalid",
+					Message: "The last part of the multipart/form-data message should be a file.",
+				})
+				continue
+			}
+			msg.ArtifactReader = part
+		}
+	}
+
+	fields = append(fields, validateUploadConstraints(msg, constraints, sawFilePart)...)
+	if len(fields) > 0 {
+		return nil, fields, nil
+	}
+
+	return msg, nil, nil
+}
+
+// validateUploadConstraints checks msg against constraints (and the
+// constraints every upload must satisfy regardless of configuration, such
+// as carrying a name and a file), returning one FieldError per violation.
+func validateUploadConstraints(msg *model.MultipartGenerateImageMsg, constraints *model.ArtifactUploadConstraints, sawFilePart bool) []model.FieldError {
+	var fields []model.FieldError
+
+	if msg.ArtifactReader == nil && !sawFilePart {
+		fields = append(fields, model.FieldError{
+			Name:    "file",
+			Code:    "required",
+			Message: "Request does not contain artifact",
+		})
+	}
+
+	if msg.MetaConstructor.Name == "" {
+		fields = append(fields, model.FieldError{
+			Name:    "name",
+			Code:    "required",
+			Message: "name is required",
+		})
+	} else if constraints != nil {
+		maxLen := constraints.MaxNameLength
+		if maxLen == 0 {
+			maxLen = model.DefaultMaxNameLength
+		}
+		if len(msg.MetaConstructor.Name) > maxLen {
+			fields = append(fields, model.FieldError{
+				Name:    "name",
+				Code:    "too_long",
+				Message: fmt.Sprintf("name must be at most %d characters", maxLen),
+			})
+		}
+		if constraints.ForbiddenNameChars != "" && strings.ContainsAny(msg.MetaConstructor.Name, constraints.ForbiddenNameChars) {
+			fields = append(fields, model.FieldError{
+				Name:    "name",
+				Code:    "invalid",
+				Message: "name contains forbidden characters",
+			})
+		}
+	}
+
+	if msg.ArtifactReader != nil && msg.ArtifactSize <= 0 {
+		fields = append(fields, model.FieldError{
+			Name:    "size",
+			Code:    "invalid",
+			Message: "No size provided before the file part of the message or the size value is wrong.",
+		})
+	} else if constraints != nil && constraints.MaxSize > 0 && msg.ArtifactSize > constraints.MaxSize {
+		fields = append(fields, model.FieldError{
+			Name:    "size",
+			Code:    "too_large",
+			Message: fmt.Sprintf("size exceeds the maximum allowed %d bytes", constraints.MaxSize),
+		})
+	}
+
+	if constraints != nil && len(constraints.AllowedTypes) > 0 && msg.Type != "" && !containsString(constraints.AllowedTypes, msg.Type) {
+		fields = append(fields, model.FieldError{
+			Name:    "type",
+			Code:    "invalid",
+			Message: fmt.Sprintf("unsupported type %q", msg.Type),
+		})
+	}
+
+	return fields
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
@@ -15,8 +15,10 @@
 package http
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"testing"
@@ -25,7 +27,9 @@ import (
 	"github.com/ant0ine/go-json-rest/rest/test"
 
 	"github.com/mendersoftware/deployments/app"
+	"github.com/mendersoftware/deployments/app/artifacttype"
 	app_mocks "github.com/mendersoftware/deployments/app/mocks"
+	"github.com/mendersoftware/deployments/model"
 	store_mocks "github.com/mendersoftware/deployments/store/mocks"
 	"github.com/mendersoftware/deployments/utils/restutil/view"
 	h "github.com/mendersoftware/deployments/utils/testing"
@@ -355,7 +359,7 @@ func TestPostArtifactsGenerate(t *testing.T) {
 				).Return(tc.appGenerateImageResponse, tc.appGenerateImageError)
 			}
 
-			d := NewDeploymentsApiHandlers(store, restView, app)
+			d := NewDeploymentsApiHandlers(store, restView, app, nil, nil)
 			api := setUpRestTest("/api/0.0.1/artifacts/generate", rest.Post, d.GenerateImage)
 			req := h.MakeMultipartRequest("POST", "http://localhost/api/0.0.1/artifacts/generate",
 				tc.requestContentType, tc.requestBodyObject)
@@ -365,8 +369,7 @@ func TestPostArtifactsGenerate(t *testing.T) {
 			if tc.responseBody == "" {
 				recorded.BodyIs(tc.responseBody)
 			} else {
-				body, _ := recorded.DecodedBody()
-				assert.Contains(t, string(body), tc.responseBody)
+				assert.Contains(t, recorded.Recorder.Body.String(), tc.responseBody)
 			}
 
 			if tc.appGenerateImage {
@@ -376,3 +379,219 @@ func TestPostArtifactsGenerate(t *testing.T) {
 	}
 
 }
+
+// TestPostArtifactsGenerateConstraints exercises each
+// model.ArtifactUploadConstraints rule independently, asserting the
+// "validation_failed" response shape.
+func TestPostArtifactsGenerateConstraints(t *testing.T) {
+	imageBody := []byte("123456790")
+
+	constraints := &model.ArtifactUploadConstraints{
+		MaxSize:            int64(len(imageBody)) - 1,
+		AllowedTypes:       []string{"single_file"},
+		MaxNameLength:      4,
+		ForbiddenNameChars: "/",
+	}
+
+	baseParts := func(name, artifactType string) []h.Part {
+		return []h.Part{
+			{FieldName: "name", FieldValue: name},
+			{FieldName: "size", FieldValue: strconv.Itoa(len(imageBody))},
+			{FieldName: "device_types_compatible", FieldValue: "Beagle Bone"},
+			{FieldName: "type", FieldValue: artifactType},
+			{FieldName: "file", ContentType: "application/octet-stream", ImageData: imageBody},
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		requestParts  []h.Part
+		expectedField model.FieldError
+	}{
+		{
+			name:          "size exceeds the configured maximum",
+			requestParts:  baseParts("ok", "single_file"),
+			expectedField: model.FieldError{Name: "size", Code: "too_large"},
+		},
+		{
+			name:          "disallowed type",
+			requestParts:  baseParts("ok", "docker_image"),
+			expectedField: model.FieldError{Name: "type", Code: "invalid"},
+		},
+		{
+			name:          "name exceeds the configured maximum length",
+			requestParts:  baseParts("too-long-a-name", "single_file"),
+			expectedField: model.FieldError{Name: "name", Code: "too_long"},
+		},
+		{
+			name:          "name contains a forbidden character",
+			requestParts:  baseParts("a/b", "single_file"),
+			expectedField: model.FieldError{Name: "name", Code: "invalid"},
+		},
+	}
+
+	store := &store_mocks.DataStore{}
+	restView := new(view.RESTView)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &app_mocks.App{}
+
+			d := NewDeploymentsApiHandlers(store, restView, a, constraints, nil)
+			api := setUpRestTest("/api/0.0.1/artifacts/generate", rest.Post, d.GenerateImage)
+			req := h.MakeMultipartRequest("POST", "http://localhost/api/0.0.1/artifacts/generate",
+				"multipart/form-data", tc.requestParts)
+
+			recorded := test.RunRequest(t, api.MakeHandler(), req)
+			recorded.CodeIs(http.StatusBadRequest)
+
+			var body view.ValidationErrorResponse
+			assert.NoError(t, recorded.DecodeJsonPayload(&body))
+
+			assert.Equal(t, "validation_failed", body.Err)
+
+			var found bool
+			for _, f := range body.Fields {
+				if f.Name == tc.expectedField.Name && f.Code == tc.expectedField.Code {
+					found = true
+					break
+				}
+			}
+			assert.True(t, found, "expected field error %+v in %+v", tc.expectedField, body.Fields)
+
+			a.AssertNotCalled(t, "GenerateImage", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+type fakeTypeProcessor struct{}
+
+func (fakeTypeProcessor) Identify(r io.Reader) (bool, error) { return true, nil }
+func (fakeTypeProcessor) Parse(ctx context.Context, args artifacttype.ParseArgs) (*model.ArtifactMetadata, error) {
+	return nil, nil
+}
+func (fakeTypeProcessor) Build(ctx context.Context, args artifacttype.BuildArgs) (io.ReadCloser, error) {
+	return nil, artifacttype.ErrBuildNotSupported
+}
+
+// extractingTypeProcessor's Parse reports metadata extracted from the
+// payload, so dispatch's handling of that metadata can be exercised
+// independently of any particular processor's extraction logic.
+type extractingTypeProcessor struct{}
+
+func (extractingTypeProcessor) Identify(r io.Reader) (bool, error) { return true, nil }
+func (extractingTypeProcessor) Parse(ctx context.Context, args artifacttype.ParseArgs) (*model.ArtifactMetadata, error) {
+	return &model.ArtifactMetadata{Name: "name-from-payload"}, nil
+}
+func (extractingTypeProcessor) Build(ctx context.Context, args artifacttype.BuildArgs) (io.ReadCloser, error) {
+	return nil, artifacttype.ErrBuildNotSupported
+}
+
+// failingTypeProcessor's Parse always rejects the payload, so dispatch
+// failures can be exercised independently of the registry lookup.
+type failingTypeProcessor struct{}
+
+func (failingTypeProcessor) Identify(r io.Reader) (bool, error) { return false, nil }
+func (failingTypeProcessor) Parse(ctx context.Context, args artifacttype.ParseArgs) (*model.ArtifactMetadata, error) {
+	return nil, errors.New("malformed payload")
+}
+func (failingTypeProcessor) Build(ctx context.Context, args artifacttype.BuildArgs) (io.ReadCloser, error) {
+	return nil, artifacttype.ErrBuildNotSupported
+}
+
+// TestPostArtifactsGenerateTypeDispatch exercises registry dispatch on the
+// "type" form field: a registered type reaches the app layer, an
+// unregistered one is rejected with the list of types that are supported.
+func TestPostArtifactsGenerateTypeDispatch(t *testing.T) {
+	imageBody := []byte("123456790")
+	parts := func(artifactType string) []h.Part {
+		return []h.Part{
+			{FieldName: "name", FieldValue: "name"},
+			{FieldName: "size", FieldValue: strconv.Itoa(len(imageBody))},
+			{FieldName: "device_types_compatible", FieldValue: "Beagle Bone"},
+			{FieldName: "type", FieldValue: artifactType},
+			{FieldName: "file", ContentType: "application/octet-stream", ImageData: imageBody},
+		}
+	}
+
+	registry := artifacttype.NewRegistry()
+	registry.Register("fake_type", fakeTypeProcessor{})
+
+	store := &store_mocks.DataStore{}
+	restView := new(view.RESTView)
+
+	t.Run("registered type reaches the app layer", func(t *testing.T) {
+		a := &app_mocks.App{}
+		a.On("GenerateImage",
+			h.ContextMatcher(),
+			mock.AnythingOfType("*model.MultipartGenerateImageMsg"),
+		).Return("artifactID", nil)
+
+		d := NewDeploymentsApiHandlers(store, restView, a, nil, registry)
+		api := setUpRestTest("/api/0.0.1/artifacts/generate", rest.Post, d.GenerateImage)
+		req := h.MakeMultipartRequest("POST", "http://localhost/api/0.0.1/artifacts/generate",
+			"multipart/form-data", parts("fake_type"))
+
+		recorded := test.RunRequest(t, api.MakeHandler(), req)
+		recorded.CodeIs(http.StatusCreated)
+		a.AssertExpectations(t)
+	})
+
+	t.Run("unregistered type is rejected with the supported list", func(t *testing.T) {
+		a := &app_mocks.App{}
+
+		d := NewDeploymentsApiHandlers(store, restView, a, nil, registry)
+		api := setUpRestTest("/api/0.0.1/artifacts/generate", rest.Post, d.GenerateImage)
+		req := h.MakeMultipartRequest("POST", "http://localhost/api/0.0.1/artifacts/generate",
+			"multipart/form-data", parts("no_such_type"))
+
+		recorded := test.RunRequest(t, api.MakeHandler(), req)
+		recorded.CodeIs(http.StatusBadRequest)
+
+		var body view.UnsupportedTypeResponse
+		assert.NoError(t, recorded.DecodeJsonPayload(&body))
+		assert.Equal(t, []string{"fake_type"}, body.SupportedTypes)
+
+		a.AssertNotCalled(t, "GenerateImage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("the registered processor's Parse runs against the upload", func(t *testing.T) {
+		failingRegistry := artifacttype.NewRegistry()
+		failingRegistry.Register("failing_type", failingTypeProcessor{})
+
+		a := &app_mocks.App{}
+
+		d := NewDeploymentsApiHandlers(store, restView, a, nil, failingRegistry)
+		api := setUpRestTest("/api/0.0.1/artifacts/generate", rest.Post, d.GenerateImage)
+		req := h.MakeMultipartRequest("POST", "http://localhost/api/0.0.1/artifacts/generate",
+			"multipart/form-data", parts("failing_type"))
+
+		recorded := test.RunRequest(t, api.MakeHandler(), req)
+		recorded.CodeIs(http.StatusBadRequest)
+		assert.Contains(t, recorded.Recorder.Body.String(), "malformed payload")
+
+		a.AssertNotCalled(t, "GenerateImage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("metadata extracted by Parse overrides the client-supplied name", func(t *testing.T) {
+		extractingRegistry := artifacttype.NewRegistry()
+		extractingRegistry.Register("extracting_type", extractingTypeProcessor{})
+
+		a := &app_mocks.App{}
+		a.On("GenerateImage",
+			h.ContextMatcher(),
+			mock.MatchedBy(func(msg *model.MultipartGenerateImageMsg) bool {
+				return msg.MetaConstructor.Name == "name-from-payload"
+			}),
+		).Return("artifactID", nil)
+
+		d := NewDeploymentsApiHandlers(store, restView, a, nil, extractingRegistry)
+		api := setUpRestTest("/api/0.0.1/artifacts/generate", rest.Post, d.GenerateImage)
+		req := h.MakeMultipartRequest("POST", "http://localhost/api/0.0.1/artifacts/generate",
+			"multipart/form-data", parts("extracting_type"))
+
+		recorded := test.RunRequest(t, api.MakeHandler(), req)
+		recorded.CodeIs(http.StatusCreated)
+		a.AssertExpectations(t)
+	})
+}
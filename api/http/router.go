@@ -0,0 +1,48 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"github.com/ant0ine/go-json-rest/rest"
+)
+
+const apiUrlArtifactsGenerate = "/api/0.0.1/artifacts/generate"
+const apiUrlArtifactsUploads = "/api/0.0.1/artifacts/uploads"
+const apiUrlArtifactsUpload = "/api/0.0.1/artifacts/uploads/:id"
+const apiUrlOpenAPISpec = "/api/0.0.1/openapi.yaml"
+const apiUrlDocs = "/api/0.0.1/docs"
+
+// NewRouter builds the rest.Api serving every route registered by d.
+func NewRouter(d *DeploymentsApiHandlers) (*rest.Api, error) {
+	router, err := rest.MakeRouter(
+		rest.Post(apiUrlArtifactsGenerate, d.GenerateImage),
+
+		rest.Post(apiUrlArtifactsUploads, d.CreateUpload),
+		rest.Head(apiUrlArtifactsUpload, d.HeadUpload),
+		rest.Patch(apiUrlArtifactsUpload, d.PatchUpload),
+		rest.Delete(apiUrlArtifactsUpload, d.DeleteUpload),
+
+		rest.Get(apiUrlOpenAPISpec, d.ServeOpenAPISpec),
+		rest.Get(apiUrlDocs, d.ServeDocs),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	api := rest.NewApi()
+	api.SetApp(router)
+
+	return api, nil
+}
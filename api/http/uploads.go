@@ -0,0 +1,330 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/mendersoftware/deployments/model"
+)
+
+const (
+	// TusResumable is the tus.io protocol version implemented here.
+	TusResumable = "1.0.0"
+	// TusVersion lists the protocol versions the server understands.
+	TusVersion = "1.0.0"
+	// TusExtension lists the tus.io extensions implemented here.
+	TusExtension = "creation,termination,expiration"
+
+	// TusMaxSize is the largest upload this server accepts, in bytes.
+	TusMaxSize = 10 * 1024 * 1024 * 1024 // 10 GiB
+
+	// uploadExpiry is how long an incomplete upload is kept before the
+	// janitor reaps it.
+	uploadExpiry = 24 * time.Hour
+
+	headerUploadLength    = "Upload-Length"
+	headerUploadOffset    = "Upload-Offset"
+	headerUploadMetadata  = "Upload-Metadata"
+	headerTusResumable    = "Tus-Resumable"
+	headerTusVersion      = "Tus-Version"
+	headerTusMaxSize      = "Tus-Max-Size"
+	headerTusExtension    = "Tus-Extension"
+	contentTypeUploadPart = "application/offset+octet-stream"
+)
+
+func setTusHeaders(w rest.ResponseWriter) {
+	w.Header().Set(headerTusResumable, TusResumable)
+	w.Header().Set(headerTusVersion, TusVersion)
+	w.Header().Set(headerTusMaxSize, strconv.Itoa(TusMaxSize))
+	w.Header().Set(headerTusExtension, TusExtension)
+}
+
+// parseUploadMetadata decodes a tus.io Upload-Metadata header of the form
+// "key1 base64value1,key2 base64value2,...".
+func parseUploadMetadata(header string) (map[string]string, error) {
+	meta := make(map[string]string)
+	if header == "" {
+		return meta, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, " ", 2)
+		key := parts[0]
+		if len(parts) == 1 {
+			meta[key] = ""
+			continue
+		}
+		value, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "Upload-Metadata: malformed value for %q", key)
+		}
+		meta[key] = string(value)
+	}
+
+	return meta, nil
+}
+
+// CreateUpload implements the tus.io Creation extension: it registers a new
+// resumable upload and returns its location. The declared size, name and
+// type are checked against the same model.ArtifactUploadConstraints and
+// artifacttype.Registry as the single-shot multipart endpoint, so a client
+// can't use this path to smuggle in what /artifacts/generate would reject.
+//
+// @Summary Start a resumable artifact upload
+// @Description Implements the tus.io Creation extension to register a new resumable upload.
+// @Tags artifacts
+// @Param Upload-Length header integer true "Total size of the upload in bytes"
+// @Param Upload-Metadata header string false "Comma-separated key/base64(value) pairs: name, description, device_types_compatible, type, args"
+// @Success 201 "Created; Location header points at the new upload resource"
+// @Failure 400 {object} view.ErrorResponse "Missing or invalid Upload-Length or Upload-Metadata, or a constraint violation"
+// @Failure 413 {object} view.ErrorResponse "Upload-Length exceeds Tus-Max-Size"
+// @Router /artifacts/uploads [post]
+func (d *DeploymentsApiHandlers) CreateUpload(w rest.ResponseWriter, r *rest.Request) {
+	setTusHeaders(w)
+
+	size, err := strconv.ParseInt(r.Header.Get(headerUploadLength), 10, 64)
+	if err != nil || size <= 0 {
+		d.view.RenderError(w, r, errors.New("Upload-Length header missing or invalid"), http.StatusBadRequest)
+		return
+	}
+	if size > TusMaxSize {
+		d.view.RenderError(w, r, errors.New("Upload-Length exceeds Tus-Max-Size"), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	meta, err := parseUploadMetadata(r.Header.Get(headerUploadMetadata))
+	if err != nil {
+		d.view.RenderError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if meta["name"] == "" {
+		d.view.RenderError(w, r, errors.New("Upload-Metadata must carry a non-empty \"name\""), http.StatusBadRequest)
+		return
+	}
+
+	var deviceTypes []string
+	if dt := meta["device_types_compatible"]; dt != "" {
+		deviceTypes = strings.Split(dt, ",")
+	}
+
+	candidate := &model.MultipartGenerateImageMsg{
+		MetaConstructor: &model.SoftwareImageMetaConstructor{
+			Name:                  meta["name"],
+			Description:           meta["description"],
+			DeviceTypesCompatible: deviceTypes,
+		},
+		ArtifactSize: size,
+		Type:         meta["type"],
+		Args:         meta["args"],
+	}
+	if fields := validateUploadConstraints(candidate, d.constraints, true); len(fields) > 0 {
+		d.view.RenderValidationError(w, r, fields, http.StatusBadRequest)
+		return
+	}
+	if d.registry != nil {
+		if _, ok := d.registry.Get(candidate.Type); !ok {
+			d.view.RenderUnsupportedType(w, r, d.registry.Types())
+			return
+		}
+	}
+
+	now := time.Now()
+	upload := &model.Upload{
+		Id:        uuid.NewV4().String(),
+		Size:      size,
+		Meta:      candidate.MetaConstructor,
+		Type:      candidate.Type,
+		Args:      candidate.Args,
+		CreatedAt: now,
+		ExpiresAt: now.Add(uploadExpiry),
+	}
+
+	if err := d.store.CreateUpload(r.Context(), upload); err != nil {
+		d.view.RenderInternalError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", r.URL.Path+"/"+upload.Id)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// HeadUpload implements the tus.io HEAD check: it reports how many bytes of
+// the named upload have been received so far.
+//
+// @Summary Check a resumable upload's progress
+// @Description Implements the tus.io HEAD check, reporting the current offset of an upload.
+// @Tags artifacts
+// @Param id path string true "Upload id"
+// @Success 200 "OK; Upload-Offset and Upload-Length headers carry the progress"
+// @Failure 404 {object} view.ErrorResponse "No such upload"
+// @Router /artifacts/uploads/{id} [head]
+func (d *DeploymentsApiHandlers) HeadUpload(w rest.ResponseWriter, r *rest.Request) {
+	setTusHeaders(w)
+
+	upload, err := d.findUpload(w, r)
+	if err != nil || upload == nil {
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set(headerUploadOffset, strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set(headerUploadLength, strconv.FormatInt(upload.Size, 10))
+	w.WriteHeader(http.StatusOK)
+}
+
+// PatchUpload implements the tus.io PATCH append: it appends a contiguous
+// chunk of bytes at Upload-Offset, and on the chunk that completes the
+// upload, runs the registered artifacttype.Processor for the upload's type
+// (exactly as GenerateImage does) before handing the assembled artifact to
+// app.GenerateImage.
+//
+// @Summary Append a chunk to a resumable upload
+// @Description Implements the tus.io PATCH append; the chunk that completes the upload triggers artifact storage.
+// @Tags artifacts
+// @Accept application/offset+octet-stream
+// @Param id path string true "Upload id"
+// @Param Upload-Offset header integer true "Offset the appended chunk starts at, must match the server's current offset"
+// @Success 204 "No Content; Upload-Offset header carries the new offset"
+// @Failure 400 {object} view.ErrorResponse "Wrong Content-Type"
+// @Failure 404 {object} view.ErrorResponse "No such upload"
+// @Failure 409 {object} view.ErrorResponse "Upload-Offset does not match the current offset"
+// @Failure 422 {object} view.ValidationErrorResponse "Artifact name is not unique or media processing failed"
+// @Failure 500 {object} view.ErrorResponse "Internal error"
+// @Router /artifacts/uploads/{id} [patch]
+func (d *DeploymentsApiHandlers) PatchUpload(w rest.ResponseWriter, r *rest.Request) {
+	setTusHeaders(w)
+
+	if r.Header.Get("Content-Type") != contentTypeUploadPart {
+		d.view.RenderError(w, r, errors.New("Content-Type must be application/offset+octet-stream"), http.StatusBadRequest)
+		return
+	}
+
+	upload, err := d.findUpload(w, r)
+	if err != nil || upload == nil {
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get(headerUploadOffset), 10, 64)
+	if err != nil || offset != upload.Offset {
+		d.view.RenderError(w, r, errors.New("Upload-Offset does not match current offset"), http.StatusConflict)
+		return
+	}
+
+	ctx := r.Context()
+	newOffset, err := d.store.AppendUploadChunk(ctx, upload.Id, offset, r.Body)
+	if err != nil {
+		d.view.RenderInternalError(w, r, err)
+		return
+	}
+	upload.Offset = newOffset
+
+	w.Header().Set(headerUploadOffset, strconv.FormatInt(upload.Offset, 10))
+
+	if !upload.IsComplete() {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	artifact, err := d.store.OpenUpload(ctx, upload.Id)
+	if err != nil {
+		d.view.RenderInternalError(w, r, err)
+		return
+	}
+	defer artifact.Close()
+
+	generateImageMsg := &model.MultipartGenerateImageMsg{
+		MetaConstructor: upload.Meta,
+		ArtifactSize:    upload.Size,
+		ArtifactReader:  artifact,
+		Type:            upload.Type,
+		Args:            upload.Args,
+		Remote:          true,
+	}
+
+	if d.registry != nil {
+		processor, ok := d.registry.Get(generateImageMsg.Type)
+		if !ok {
+			d.view.RenderUnsupportedType(w, r, d.registry.Types())
+			return
+		}
+		if err := dispatchArtifactType(ctx, processor, generateImageMsg); err != nil {
+			d.view.RenderError(w, r, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if _, err := d.app.GenerateImage(ctx, generateImageMsg); err != nil {
+		d.renderGenerateImageError(w, r, err)
+		return
+	}
+
+	if err := d.store.DeleteUpload(ctx, upload.Id); err != nil {
+		d.view.RenderInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteUpload implements the tus.io Termination extension: it abandons an
+// incomplete upload and frees its scratch storage.
+//
+// @Summary Abandon a resumable upload
+// @Description Implements the tus.io Termination extension, discarding an incomplete upload.
+// @Tags artifacts
+// @Param id path string true "Upload id"
+// @Success 204 "No Content"
+// @Failure 500 {object} view.ErrorResponse "Internal error"
+// @Router /artifacts/uploads/{id} [delete]
+func (d *DeploymentsApiHandlers) DeleteUpload(w rest.ResponseWriter, r *rest.Request) {
+	setTusHeaders(w)
+
+	id := r.PathParam("id")
+	if err := d.store.DeleteUpload(r.Context(), id); err != nil {
+		d.view.RenderInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (d *DeploymentsApiHandlers) findUpload(w rest.ResponseWriter, r *rest.Request) (*model.Upload, error) {
+	id := r.PathParam("id")
+
+	upload, err := d.store.FindUpload(r.Context(), id)
+	if err != nil {
+		d.view.RenderInternalError(w, r, err)
+		return nil, err
+	}
+	if upload == nil {
+		d.view.RenderError(w, r, errors.New("Upload not found"), http.StatusNotFound)
+		return nil, errors.New("not found")
+	}
+
+	return upload, nil
+}
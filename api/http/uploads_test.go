@@ -0,0 +1,346 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/ant0ine/go-json-rest/rest/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/deployments/app"
+	app_mocks "github.com/mendersoftware/deployments/app/mocks"
+	"github.com/mendersoftware/deployments/app/artifacttype"
+	"github.com/mendersoftware/deployments/model"
+	store_mocks "github.com/mendersoftware/deployments/store/mocks"
+	"github.com/mendersoftware/deployments/utils/restutil/view"
+	h "github.com/mendersoftware/deployments/utils/testing"
+)
+
+func tusMetadata(pairs map[string]string) string {
+	var parts []string
+	for k, v := range pairs {
+		parts = append(parts, k+" "+base64.StdEncoding.EncodeToString([]byte(v)))
+	}
+	s := ""
+	for i, p := range parts {
+		if i > 0 {
+			s += ","
+		}
+		s += p
+	}
+	return s
+}
+
+func TestPostArtifactsUploads(t *testing.T) {
+	testCases := []struct {
+		name           string
+		uploadLength   string
+		uploadMetadata string
+		storeErr       error
+		responseCode   int
+	}{
+		{
+			name:           "ok",
+			uploadLength:   "1024",
+			uploadMetadata: tusMetadata(map[string]string{"name": "artifact"}),
+			responseCode:   http.StatusCreated,
+		},
+		{
+			name:           "missing Upload-Length",
+			uploadMetadata: tusMetadata(map[string]string{"name": "artifact"}),
+			responseCode:   http.StatusBadRequest,
+		},
+		{
+			name:           "Upload-Length exceeds Tus-Max-Size",
+			uploadLength:   strconv.Itoa(TusMaxSize + 1),
+			uploadMetadata: tusMetadata(map[string]string{"name": "artifact"}),
+			responseCode:   http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "missing name in Upload-Metadata",
+			uploadLength:   "1024",
+			uploadMetadata: tusMetadata(map[string]string{"description": "no name"}),
+			responseCode:   http.StatusBadRequest,
+		},
+		{
+			name:           "store failure",
+			uploadLength:   "1024",
+			uploadMetadata: tusMetadata(map[string]string{"name": "artifact"}),
+			storeErr:       errors.New("db down"),
+			responseCode:   http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &store_mocks.DataStore{}
+			store.On("CreateUpload", h.ContextMatcher(), mock.AnythingOfType("*model.Upload")).
+				Return(tc.storeErr)
+
+			d := NewDeploymentsApiHandlers(store, new(view.RESTView), &app_mocks.App{}, nil, nil)
+			api := setUpRestTest(apiUrlArtifactsUploads, rest.Post, d.CreateUpload)
+
+			req, err := http.NewRequest(http.MethodPost, "http://localhost"+apiUrlArtifactsUploads, nil)
+			assert.NoError(t, err)
+			if tc.uploadLength != "" {
+				req.Header.Set(headerUploadLength, tc.uploadLength)
+			}
+			req.Header.Set(headerUploadMetadata, tc.uploadMetadata)
+
+			recorded := test.RunRequest(t, api.MakeHandler(), req)
+			recorded.CodeIs(tc.responseCode)
+			recorded.HeaderIs(headerTusResumable, TusResumable)
+
+			if tc.responseCode == http.StatusCreated {
+				assert.NotEmpty(t, recorded.Recorder.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestPostArtifactsUploadsConstraintsAndRegistry(t *testing.T) {
+	t.Run("rejects an upload exceeding the configured max size", func(t *testing.T) {
+		store := &store_mocks.DataStore{}
+		constraints := &model.ArtifactUploadConstraints{MaxSize: 100}
+
+		d := NewDeploymentsApiHandlers(store, new(view.RESTView), &app_mocks.App{}, constraints, nil)
+		api := setUpRestTest(apiUrlArtifactsUploads, rest.Post, d.CreateUpload)
+
+		req, err := http.NewRequest(http.MethodPost, "http://localhost"+apiUrlArtifactsUploads, nil)
+		assert.NoError(t, err)
+		req.Header.Set(headerUploadLength, "1024")
+		req.Header.Set(headerUploadMetadata, tusMetadata(map[string]string{"name": "artifact"}))
+
+		recorded := test.RunRequest(t, api.MakeHandler(), req)
+		recorded.CodeIs(http.StatusBadRequest)
+		store.AssertNotCalled(t, "CreateUpload", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects an unregistered type", func(t *testing.T) {
+		store := &store_mocks.DataStore{}
+		registry := artifacttype.NewRegistry()
+		registry.Register("fake_type", fakeTypeProcessor{})
+
+		d := NewDeploymentsApiHandlers(store, new(view.RESTView), &app_mocks.App{}, nil, registry)
+		api := setUpRestTest(apiUrlArtifactsUploads, rest.Post, d.CreateUpload)
+
+		req, err := http.NewRequest(http.MethodPost, "http://localhost"+apiUrlArtifactsUploads, nil)
+		assert.NoError(t, err)
+		req.Header.Set(headerUploadLength, "1024")
+		req.Header.Set(headerUploadMetadata,
+			tusMetadata(map[string]string{"name": "artifact", "type": "no_such_type"}))
+
+		recorded := test.RunRequest(t, api.MakeHandler(), req)
+		recorded.CodeIs(http.StatusBadRequest)
+		store.AssertNotCalled(t, "CreateUpload", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHeadUpload(t *testing.T) {
+	t.Run("reports the current offset", func(t *testing.T) {
+		store := &store_mocks.DataStore{}
+		store.On("FindUpload", h.ContextMatcher(), "upload1").
+			Return(&model.Upload{Id: "upload1", Size: 100, Offset: 40}, nil)
+
+		d := NewDeploymentsApiHandlers(store, new(view.RESTView), &app_mocks.App{}, nil, nil)
+		api := setUpRestTest(apiUrlArtifactsUpload, rest.Head, d.HeadUpload)
+
+		req, err := http.NewRequest(http.MethodHead, "http://localhost/api/0.0.1/artifacts/uploads/upload1", nil)
+		assert.NoError(t, err)
+
+		recorded := test.RunRequest(t, api.MakeHandler(), req)
+		recorded.CodeIs(http.StatusOK)
+		recorded.HeaderIs(headerUploadOffset, "40")
+		recorded.HeaderIs(headerUploadLength, "100")
+	})
+
+	t.Run("no such upload", func(t *testing.T) {
+		store := &store_mocks.DataStore{}
+		store.On("FindUpload", h.ContextMatcher(), "missing").Return(nil, nil)
+
+		d := NewDeploymentsApiHandlers(store, new(view.RESTView), &app_mocks.App{}, nil, nil)
+		api := setUpRestTest(apiUrlArtifactsUpload, rest.Head, d.HeadUpload)
+
+		req, err := http.NewRequest(http.MethodHead, "http://localhost/api/0.0.1/artifacts/uploads/missing", nil)
+		assert.NoError(t, err)
+
+		recorded := test.RunRequest(t, api.MakeHandler(), req)
+		recorded.CodeIs(http.StatusNotFound)
+	})
+}
+
+func TestPatchUpload(t *testing.T) {
+	t.Run("appends a chunk that does not complete the upload", func(t *testing.T) {
+		store := &store_mocks.DataStore{}
+		store.On("FindUpload", h.ContextMatcher(), "upload1").
+			Return(&model.Upload{Id: "upload1", Size: 100, Offset: 40}, nil)
+		store.On("AppendUploadChunk", h.ContextMatcher(), "upload1", int64(40), mock.Anything).
+			Return(int64(60), nil)
+
+		a := &app_mocks.App{}
+		d := NewDeploymentsApiHandlers(store, new(view.RESTView), a, nil, nil)
+		api := setUpRestTest(apiUrlArtifactsUpload, rest.Patch, d.PatchUpload)
+
+		req, err := http.NewRequest(http.MethodPatch, "http://localhost/api/0.0.1/artifacts/uploads/upload1",
+			bytes.NewReader([]byte("chunk")))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", contentTypeUploadPart)
+		req.Header.Set(headerUploadOffset, "40")
+
+		recorded := test.RunRequest(t, api.MakeHandler(), req)
+		recorded.CodeIs(http.StatusNoContent)
+		recorded.HeaderIs(headerUploadOffset, "60")
+		a.AssertNotCalled(t, "GenerateImage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("the chunk that completes the upload triggers GenerateImage", func(t *testing.T) {
+		store := &store_mocks.DataStore{}
+		store.On("FindUpload", h.ContextMatcher(), "upload1").
+			Return(&model.Upload{Id: "upload1", Size: 100, Offset: 90}, nil)
+		store.On("AppendUploadChunk", h.ContextMatcher(), "upload1", int64(90), mock.Anything).
+			Return(int64(100), nil)
+		store.On("OpenUpload", h.ContextMatcher(), "upload1").
+			Return(ioutil.NopCloser(bytes.NewReader([]byte("artifact bytes"))), nil)
+		store.On("DeleteUpload", h.ContextMatcher(), "upload1").Return(nil)
+
+		a := &app_mocks.App{}
+		a.On("GenerateImage", h.ContextMatcher(), mock.AnythingOfType("*model.MultipartGenerateImageMsg")).
+			Return("imgID", nil)
+
+		d := NewDeploymentsApiHandlers(store, new(view.RESTView), a, nil, nil)
+		api := setUpRestTest(apiUrlArtifactsUpload, rest.Patch, d.PatchUpload)
+
+		req, err := http.NewRequest(http.MethodPatch, "http://localhost/api/0.0.1/artifacts/uploads/upload1",
+			bytes.NewReader([]byte("chunk")))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", contentTypeUploadPart)
+		req.Header.Set(headerUploadOffset, "90")
+
+		recorded := test.RunRequest(t, api.MakeHandler(), req)
+		recorded.CodeIs(http.StatusNoContent)
+		a.AssertExpectations(t)
+		store.AssertCalled(t, "DeleteUpload", h.ContextMatcher(), "upload1")
+	})
+
+	t.Run("GenerateImage failure maps to the same status as the multipart endpoint", func(t *testing.T) {
+		store := &store_mocks.DataStore{}
+		store.On("FindUpload", h.ContextMatcher(), "upload1").
+			Return(&model.Upload{Id: "upload1", Size: 100, Offset: 90}, nil)
+		store.On("AppendUploadChunk", h.ContextMatcher(), "upload1", int64(90), mock.Anything).
+			Return(int64(100), nil)
+		store.On("OpenUpload", h.ContextMatcher(), "upload1").
+			Return(ioutil.NopCloser(bytes.NewReader([]byte("artifact bytes"))), nil)
+
+		a := &app_mocks.App{}
+		a.On("GenerateImage", h.ContextMatcher(), mock.AnythingOfType("*model.MultipartGenerateImageMsg")).
+			Return("", app.ErrModelArtifactNotUnique)
+
+		d := NewDeploymentsApiHandlers(store, new(view.RESTView), a, nil, nil)
+		api := setUpRestTest(apiUrlArtifactsUpload, rest.Patch, d.PatchUpload)
+
+		req, err := http.NewRequest(http.MethodPatch, "http://localhost/api/0.0.1/artifacts/uploads/upload1",
+			bytes.NewReader([]byte("chunk")))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", contentTypeUploadPart)
+		req.Header.Set(headerUploadOffset, "90")
+
+		recorded := test.RunRequest(t, api.MakeHandler(), req)
+		recorded.CodeIs(http.StatusUnprocessableEntity)
+		store.AssertNotCalled(t, "DeleteUpload", mock.Anything, mock.Anything)
+	})
+
+	t.Run("wrong Content-Type is rejected", func(t *testing.T) {
+		store := &store_mocks.DataStore{}
+		d := NewDeploymentsApiHandlers(store, new(view.RESTView), &app_mocks.App{}, nil, nil)
+		api := setUpRestTest(apiUrlArtifactsUpload, rest.Patch, d.PatchUpload)
+
+		req, err := http.NewRequest(http.MethodPatch, "http://localhost/api/0.0.1/artifacts/uploads/upload1",
+			bytes.NewReader([]byte("chunk")))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/octet-stream")
+
+		recorded := test.RunRequest(t, api.MakeHandler(), req)
+		recorded.CodeIs(http.StatusBadRequest)
+	})
+
+	t.Run("the completing chunk is rejected by the registered processor", func(t *testing.T) {
+		store := &store_mocks.DataStore{}
+		store.On("FindUpload", h.ContextMatcher(), "upload1").
+			Return(&model.Upload{Id: "upload1", Size: 100, Offset: 90, Type: "failing_type"}, nil)
+		store.On("AppendUploadChunk", h.ContextMatcher(), "upload1", int64(90), mock.Anything).
+			Return(int64(100), nil)
+		store.On("OpenUpload", h.ContextMatcher(), "upload1").
+			Return(ioutil.NopCloser(bytes.NewReader([]byte("artifact bytes"))), nil)
+
+		registry := artifacttype.NewRegistry()
+		registry.Register("failing_type", failingTypeProcessor{})
+
+		a := &app_mocks.App{}
+		d := NewDeploymentsApiHandlers(store, new(view.RESTView), a, nil, registry)
+		api := setUpRestTest(apiUrlArtifactsUpload, rest.Patch, d.PatchUpload)
+
+		req, err := http.NewRequest(http.MethodPatch, "http://localhost/api/0.0.1/artifacts/uploads/upload1",
+			bytes.NewReader([]byte("chunk")))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", contentTypeUploadPart)
+		req.Header.Set(headerUploadOffset, "90")
+
+		recorded := test.RunRequest(t, api.MakeHandler(), req)
+		recorded.CodeIs(http.StatusBadRequest)
+		a.AssertNotCalled(t, "GenerateImage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("mismatched Upload-Offset is a conflict", func(t *testing.T) {
+		store := &store_mocks.DataStore{}
+		store.On("FindUpload", h.ContextMatcher(), "upload1").
+			Return(&model.Upload{Id: "upload1", Size: 100, Offset: 40}, nil)
+
+		d := NewDeploymentsApiHandlers(store, new(view.RESTView), &app_mocks.App{}, nil, nil)
+		api := setUpRestTest(apiUrlArtifactsUpload, rest.Patch, d.PatchUpload)
+
+		req, err := http.NewRequest(http.MethodPatch, "http://localhost/api/0.0.1/artifacts/uploads/upload1",
+			bytes.NewReader([]byte("chunk")))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", contentTypeUploadPart)
+		req.Header.Set(headerUploadOffset, "0")
+
+		recorded := test.RunRequest(t, api.MakeHandler(), req)
+		recorded.CodeIs(http.StatusConflict)
+	})
+}
+
+func TestDeleteUpload(t *testing.T) {
+	store := &store_mocks.DataStore{}
+	store.On("DeleteUpload", h.ContextMatcher(), "upload1").Return(nil)
+
+	d := NewDeploymentsApiHandlers(store, new(view.RESTView), &app_mocks.App{}, nil, nil)
+	api := setUpRestTest(apiUrlArtifactsUpload, rest.Delete, d.DeleteUpload)
+
+	req, err := http.NewRequest(http.MethodDelete, "http://localhost/api/0.0.1/artifacts/uploads/upload1", nil)
+	assert.NoError(t, err)
+
+	recorded := test.RunRequest(t, api.MakeHandler(), req)
+	recorded.CodeIs(http.StatusNoContent)
+	store.AssertExpectations(t)
+}
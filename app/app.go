@@ -0,0 +1,86 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+
+	"github.com/mendersoftware/deployments/app/processing"
+	"github.com/mendersoftware/deployments/model"
+	"github.com/mendersoftware/deployments/store"
+)
+
+// defaultMediaMaxSize is used for MediaLocalMaxSize/MediaRemoteMaxSize
+// when NewDeployments is not given an explicit limit.
+const defaultMediaMaxSize = 1024 * 1024 * 1024 // 1 GiB
+
+// App is the interface exposed by the application layer to the API and
+// other consumers.
+type App interface {
+	// GenerateImage parses and stores an artifact uploaded via the
+	// multipart/form-data request described by multipartUploadMsg,
+	// returning the id assigned to the new image.
+	GenerateImage(ctx context.Context, multipartUploadMsg *model.MultipartGenerateImageMsg) (string, error)
+
+	// ReapExpiredUploads deletes the bookkeeping state and scratch
+	// storage of tus.io uploads that were never completed before their
+	// expiry, and is run periodically by the upload janitor.
+	ReapExpiredUploads(ctx context.Context) error
+}
+
+// Deployments is the default implementation of App.
+type Deployments struct {
+	db store.DataStore
+
+	mediaProcessor     processing.MediaProcessor
+	mediaLocalMaxSize  int64
+	mediaRemoteMaxSize int64
+}
+
+// Option configures optional Deployments behavior.
+type Option func(*Deployments)
+
+// WithMediaProcessor enables media bundle processing (ffprobe/ffmpeg
+// normalization) for artifacts uploaded with type "media".
+func WithMediaProcessor(p processing.MediaProcessor) Option {
+	return func(d *Deployments) {
+		d.mediaProcessor = p
+	}
+}
+
+// WithMediaMaxSize sets the maximum accepted size, in bytes, of a media
+// bundle artifact uploaded from a local network (localMax) versus over a
+// remote/WAN connection (remoteMax).
+func WithMediaMaxSize(localMax, remoteMax int64) Option {
+	return func(d *Deployments) {
+		d.mediaLocalMaxSize = localMax
+		d.mediaRemoteMaxSize = remoteMax
+	}
+}
+
+// NewDeployments creates a Deployments app backed by the given data store.
+func NewDeployments(db store.DataStore, options ...Option) *Deployments {
+	d := &Deployments{
+		db:                 db,
+		mediaLocalMaxSize:  defaultMediaMaxSize,
+		mediaRemoteMaxSize: defaultMediaMaxSize,
+	}
+
+	for _, opt := range options {
+		opt(d)
+	}
+
+	return d
+}
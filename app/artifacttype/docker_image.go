@@ -0,0 +1,68 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package artifacttype
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/deployments/model"
+)
+
+// RegistryClient pulls a container image's layers from an OCI/Docker
+// registry, for DockerImage.Build to pack into an artifact.
+type RegistryClient interface {
+	PullLayers(ctx context.Context, ref string) (io.ReadCloser, error)
+}
+
+// DockerImage is the Processor for "docker_image" artifacts: rather than
+// being uploaded, it is built server-side by pulling and packing the
+// layers of a referenced container image.
+type DockerImage struct {
+	registry RegistryClient
+}
+
+// NewDockerImage creates a DockerImage processor backed by the given
+// registry client. registry may be nil; Build then fails with a
+// descriptive error instead of panicking.
+func NewDockerImage(registry RegistryClient) *DockerImage {
+	return &DockerImage{registry: registry}
+}
+
+// Identify always fails: docker_image artifacts are built from a registry
+// reference, never uploaded directly.
+func (p *DockerImage) Identify(r io.Reader) (bool, error) {
+	return false, nil
+}
+
+// Parse is not supported: docker_image artifacts are built, not parsed
+// from an upload.
+func (p *DockerImage) Parse(ctx context.Context, args ParseArgs) (*model.ArtifactMetadata, error) {
+	return nil, errors.New("docker_image: artifacts are built from a registry reference, not parsed from an upload")
+}
+
+// Build pulls and packs the layers of the image referenced by args.Args.
+func (p *DockerImage) Build(ctx context.Context, args BuildArgs) (io.ReadCloser, error) {
+	if p.registry == nil {
+		return nil, errors.New("docker_image: no registry client configured")
+	}
+	if args.Args == "" {
+		return nil, errors.New(`docker_image: requires an image reference in the "args" field`)
+	}
+
+	return p.registry.PullLayers(ctx, args.Args)
+}
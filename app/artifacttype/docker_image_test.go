@@ -0,0 +1,77 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package artifacttype
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRegistryClient struct {
+	layers []byte
+	err    error
+}
+
+func (c *fakeRegistryClient) PullLayers(ctx context.Context, ref string) (io.ReadCloser, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return ioutil.NopCloser(strings.NewReader(string(c.layers))), nil
+}
+
+func TestDockerImageBuild(t *testing.T) {
+	registry := &fakeRegistryClient{layers: []byte("layer-bytes")}
+	p := NewDockerImage(registry)
+
+	rc, err := p.Build(context.Background(), BuildArgs{Args: "docker.io/library/alpine:latest"})
+	assert.NoError(t, err)
+	defer rc.Close()
+
+	data, err := ioutil.ReadAll(rc)
+	assert.NoError(t, err)
+	assert.Equal(t, "layer-bytes", string(data))
+}
+
+func TestDockerImageBuildMalformed(t *testing.T) {
+	t.Run("missing image reference", func(t *testing.T) {
+		p := NewDockerImage(&fakeRegistryClient{})
+		_, err := p.Build(context.Background(), BuildArgs{})
+		assert.Error(t, err)
+	})
+
+	t.Run("no registry client configured", func(t *testing.T) {
+		p := NewDockerImage(nil)
+		_, err := p.Build(context.Background(), BuildArgs{Args: "alpine:latest"})
+		assert.Error(t, err)
+	})
+
+	t.Run("registry pull failure", func(t *testing.T) {
+		p := NewDockerImage(&fakeRegistryClient{err: errors.New("registry unreachable")})
+		_, err := p.Build(context.Background(), BuildArgs{Args: "alpine:latest"})
+		assert.Error(t, err)
+	})
+}
+
+func TestDockerImageParseNotSupported(t *testing.T) {
+	p := NewDockerImage(&fakeRegistryClient{})
+	_, err := p.Parse(context.Background(), ParseArgs{})
+	assert.Error(t, err)
+}
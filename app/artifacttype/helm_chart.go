@@ -0,0 +1,107 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package artifacttype
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/deployments/model"
+)
+
+// HelmChart is the Processor for "helm_chart" artifacts: a gzipped tar
+// archive (.tgz) containing a Chart.yaml.
+type HelmChart struct{}
+
+// NewHelmChart creates a HelmChart processor.
+func NewHelmChart() *HelmChart {
+	return &HelmChart{}
+}
+
+// Identify reports whether r is a gzipped tar archive containing a
+// Chart.yaml.
+func (p *HelmChart) Identify(r io.Reader) (bool, error) {
+	_, err := findChartYaml(r)
+	if err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+// Parse extracts the chart's name from its Chart.yaml.
+func (p *HelmChart) Parse(ctx context.Context, args ParseArgs) (*model.ArtifactMetadata, error) {
+	chartYaml, err := findChartYaml(args.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := parseChartName(chartYaml)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ArtifactMetadata{Name: name}, nil
+}
+
+// Build is not supported: a helm_chart artifact is always uploaded
+// pre-built.
+func (p *HelmChart) Build(ctx context.Context, args BuildArgs) (io.ReadCloser, error) {
+	return nil, ErrBuildNotSupported
+}
+
+// findChartYaml locates and returns the contents of the Chart.yaml entry
+// of a gzipped tar archive.
+func findChartYaml(r io.Reader) ([]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "helm_chart: not a gzip archive")
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, errors.New("helm_chart: archive does not contain a Chart.yaml")
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "helm_chart: fail to read archive")
+		}
+		if strings.HasSuffix(hdr.Name, "Chart.yaml") {
+			data, err := ioutil.ReadAll(tr)
+			if err != nil {
+				return nil, errors.Wrap(err, "helm_chart: fail to read Chart.yaml")
+			}
+			return data, nil
+		}
+	}
+}
+
+// parseChartName extracts the "name:" field of a Chart.yaml.
+func parseChartName(chartYaml []byte) (string, error) {
+	for _, line := range strings.Split(string(chartYaml), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "name:") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "name:")), nil
+		}
+	}
+	return "", errors.New("helm_chart: Chart.yaml does not declare a name")
+}
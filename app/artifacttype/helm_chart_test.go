@@ -0,0 +1,89 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package artifacttype
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildChartTgz(t *testing.T, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Size: int64(len(content)), Mode: 0644}
+		assert.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+
+	return buf.Bytes()
+}
+
+func TestHelmChartIdentifyAndParse(t *testing.T) {
+	archive := buildChartTgz(t, map[string]string{
+		"mychart/Chart.yaml": "name: mychart\nversion: 1.0.0\n",
+		"mychart/values.yaml": "replicaCount: 1\n",
+	})
+
+	p := NewHelmChart()
+
+	ok, err := p.Identify(bytes.NewReader(archive))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	meta, err := p.Parse(context.Background(), ParseArgs{Reader: bytes.NewReader(archive)})
+	assert.NoError(t, err)
+	assert.Equal(t, "mychart", meta.Name)
+}
+
+func TestHelmChartParseMalformed(t *testing.T) {
+	p := NewHelmChart()
+
+	t.Run("not a gzip archive", func(t *testing.T) {
+		_, err := p.Parse(context.Background(), ParseArgs{Reader: strings.NewReader("not a tarball")})
+		assert.Error(t, err)
+	})
+
+	t.Run("gzip archive without Chart.yaml", func(t *testing.T) {
+		archive := buildChartTgz(t, map[string]string{"mychart/values.yaml": "replicaCount: 1\n"})
+		_, err := p.Parse(context.Background(), ParseArgs{Reader: bytes.NewReader(archive)})
+		assert.Error(t, err)
+	})
+
+	t.Run("Chart.yaml without a name", func(t *testing.T) {
+		archive := buildChartTgz(t, map[string]string{"mychart/Chart.yaml": "version: 1.0.0\n"})
+		_, err := p.Parse(context.Background(), ParseArgs{Reader: bytes.NewReader(archive)})
+		assert.Error(t, err)
+	})
+}
+
+func TestHelmChartBuildNotSupported(t *testing.T) {
+	p := NewHelmChart()
+
+	_, err := p.Build(context.Background(), BuildArgs{})
+	assert.Equal(t, ErrBuildNotSupported, err)
+}
@@ -0,0 +1,59 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package artifacttype provides a pluggable registry of artifact type
+// processors, one per "type" form field value accepted by
+// DeploymentsApiHandlers.GenerateImage.
+package artifacttype
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/mendersoftware/deployments/model"
+)
+
+// ErrBuildNotSupported is returned by Processor.Build for types that are
+// only ever uploaded pre-built, never assembled server-side.
+var ErrBuildNotSupported = errors.New("artifacttype: processor does not support Build")
+
+// ParseArgs carries the inputs a Processor.Parse call needs to extract
+// metadata from an artifact payload.
+type ParseArgs struct {
+	Reader io.Reader
+	Args   string
+}
+
+// BuildArgs carries the inputs a Processor.Build call needs to assemble an
+// artifact stream from its constituent parts.
+type BuildArgs struct {
+	Meta *model.SoftwareImageMetaConstructor
+	Args string
+}
+
+// Processor implements the type-specific logic for one artifact type:
+// recognizing a payload, extracting its metadata, and, for types that
+// support it, building an artifact from its parts.
+type Processor interface {
+	// Identify reports whether r looks like a payload this processor
+	// handles.
+	Identify(r io.Reader) (bool, error)
+	// Parse extracts artifact metadata from the payload described by
+	// args.
+	Parse(ctx context.Context, args ParseArgs) (*model.ArtifactMetadata, error)
+	// Build assembles an artifact stream from args, or returns
+	// ErrBuildNotSupported if this type cannot be built server-side.
+	Build(ctx context.Context, args BuildArgs) (io.ReadCloser, error)
+}
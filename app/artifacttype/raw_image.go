@@ -0,0 +1,59 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package artifacttype
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/mendersoftware/deployments/model"
+)
+
+// RawImage is the Processor for "raw_image" artifacts: a disk image
+// written verbatim to a device partition, with no container format of its
+// own.
+type RawImage struct{}
+
+// NewRawImage creates a RawImage processor.
+func NewRawImage() *RawImage {
+	return &RawImage{}
+}
+
+// Identify reports whether r has any content at all; a raw image has no
+// magic number to check beyond that.
+func (p *RawImage) Identify(r io.Reader) (bool, error) {
+	buf := make([]byte, 1)
+	n, err := r.Read(buf)
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Parse returns no extracted metadata: a raw image carries none beyond the
+// client-supplied metadata fields.
+func (p *RawImage) Parse(ctx context.Context, args ParseArgs) (*model.ArtifactMetadata, error) {
+	if args.Reader == nil {
+		return nil, errors.New("raw_image: missing payload")
+	}
+	return nil, nil
+}
+
+// Build is not supported: a raw_image artifact is always uploaded
+// pre-built.
+func (p *RawImage) Build(ctx context.Context, args BuildArgs) (io.ReadCloser, error) {
+	return nil, ErrBuildNotSupported
+}
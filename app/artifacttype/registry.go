@@ -0,0 +1,62 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package artifacttype
+
+import "sort"
+
+// Registry looks up the Processor that handles a given "type" form field
+// value.
+type Registry struct {
+	processors map[string]Processor
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{processors: make(map[string]Processor)}
+}
+
+// NewDefaultRegistry creates a Registry pre-populated with the built-in
+// processors: single_file, raw_image, docker_image and helm_chart.
+// dockerRegistry may be nil; the docker_image processor will then fail at
+// Build time rather than at registration time.
+func NewDefaultRegistry(dockerRegistry RegistryClient) *Registry {
+	r := NewRegistry()
+	r.Register("single_file", NewSingleFile())
+	r.Register("raw_image", NewRawImage())
+	r.Register("docker_image", NewDockerImage(dockerRegistry))
+	r.Register("helm_chart", NewHelmChart())
+	return r
+}
+
+// Register adds (or replaces) the processor handling the given type name.
+func (r *Registry) Register(name string, p Processor) {
+	r.processors[name] = p
+}
+
+// Get looks up the processor registered for name.
+func (r *Registry) Get(name string) (Processor, bool) {
+	p, ok := r.processors[name]
+	return p, ok
+}
+
+// Types lists every registered type name, sorted.
+func (r *Registry) Types() []string {
+	names := make([]string, 0, len(r.processors))
+	for name := range r.processors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,71 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package artifacttype
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/deployments/model"
+)
+
+type fakeProcessor struct {
+	identified bool
+}
+
+func (p *fakeProcessor) Identify(r io.Reader) (bool, error) {
+	return p.identified, nil
+}
+
+func (p *fakeProcessor) Parse(ctx context.Context, args ParseArgs) (*model.ArtifactMetadata, error) {
+	return &model.ArtifactMetadata{Name: "fake"}, nil
+}
+
+func (p *fakeProcessor) Build(ctx context.Context, args BuildArgs) (io.ReadCloser, error) {
+	return nil, ErrBuildNotSupported
+}
+
+func TestRegistryDispatch(t *testing.T) {
+	r := NewRegistry()
+
+	_, ok := r.Get("fake_type")
+	assert.False(t, ok)
+	assert.Empty(t, r.Types())
+
+	fake := &fakeProcessor{identified: true}
+	r.Register("fake_type", fake)
+
+	p, ok := r.Get("fake_type")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"fake_type"}, r.Types())
+
+	meta, err := p.Parse(context.Background(), ParseArgs{})
+	assert.NoError(t, err)
+	assert.Equal(t, "fake", meta.Name)
+}
+
+func TestNewDefaultRegistryListsBuiltins(t *testing.T) {
+	r := NewDefaultRegistry(nil)
+
+	assert.Equal(t, []string{
+		"docker_image",
+		"helm_chart",
+		"raw_image",
+		"single_file",
+	}, r.Types())
+}
@@ -0,0 +1,49 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package artifacttype
+
+import (
+	"context"
+	"io"
+
+	"github.com/mendersoftware/deployments/model"
+)
+
+// SingleFile is the Processor backing the original "single_file" artifact
+// type: the uploaded payload is stored byte for byte, with no inspection
+// beyond the client-supplied metadata fields.
+type SingleFile struct{}
+
+// NewSingleFile creates a SingleFile processor.
+func NewSingleFile() *SingleFile {
+	return &SingleFile{}
+}
+
+// Identify always succeeds: single_file accepts any payload.
+func (p *SingleFile) Identify(r io.Reader) (bool, error) {
+	return true, nil
+}
+
+// Parse returns no extracted metadata: single_file relies entirely on the
+// client-supplied metadata fields.
+func (p *SingleFile) Parse(ctx context.Context, args ParseArgs) (*model.ArtifactMetadata, error) {
+	return nil, nil
+}
+
+// Build is not supported: a single_file artifact is always uploaded
+// pre-built.
+func (p *SingleFile) Build(ctx context.Context, args BuildArgs) (io.ReadCloser, error) {
+	return nil, ErrBuildNotSupported
+}
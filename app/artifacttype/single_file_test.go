@@ -0,0 +1,46 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package artifacttype
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSingleFileIdentify(t *testing.T) {
+	p := NewSingleFile()
+
+	ok, err := p.Identify(strings.NewReader("anything at all"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestSingleFileParseReturnsNoMetadata(t *testing.T) {
+	p := NewSingleFile()
+
+	meta, err := p.Parse(context.Background(), ParseArgs{Reader: strings.NewReader("data")})
+	assert.NoError(t, err)
+	assert.Nil(t, meta)
+}
+
+func TestSingleFileBuildNotSupported(t *testing.T) {
+	p := NewSingleFile()
+
+	_, err := p.Build(context.Background(), BuildArgs{})
+	assert.Equal(t, ErrBuildNotSupported, err)
+}
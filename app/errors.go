@@ -0,0 +1,46 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import "errors"
+
+var (
+	// ErrModelArtifactNotUnique is returned when an artifact with the
+	// same name already exists.
+	ErrModelArtifactNotUnique = errors.New("Artifact not unique")
+	// ErrModelArtifactFileTooLarge is returned when the uploaded artifact
+	// exceeds the configured size limit.
+	ErrModelArtifactFileTooLarge = errors.New("Artifact file too large")
+	// ErrModelParsingArtifactFailed is returned when the artifact stream
+	// could not be parsed.
+	ErrModelParsingArtifactFailed = errors.New("Cannot parse artifact file")
+	// ErrModelMultipartUploadMsgMalformed is returned when the multipart/
+	// form-data body of an upload request is malformed.
+	ErrModelMultipartUploadMsgMalformed = errors.New("Multipart upload message malformed")
+	// ErrModelMissingInputMetadata is returned when required metadata is
+	// missing from an upload request.
+	ErrModelMissingInputMetadata = errors.New("Missing input metadata")
+
+	// ErrModelMediaTooLarge is returned when a media bundle artifact
+	// exceeds the configured media size limit.
+	ErrModelMediaTooLarge = errors.New("Media payload too large")
+	// ErrModelMediaUnsupportedCodec is returned when a media bundle
+	// contains a stream whose codec is not supported.
+	ErrModelMediaUnsupportedCodec = errors.New("Unsupported media codec")
+	// ErrModelMediaProcessingFailed is returned when the media
+	// processing pipeline (ffprobe/ffmpeg) failed to handle the
+	// artifact.
+	ErrModelMediaProcessingFailed = errors.New("Failed to process media artifact")
+)
@@ -0,0 +1,116 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/mendersoftware/deployments/app/processing"
+	"github.com/mendersoftware/deployments/model"
+)
+
+// ArtifactTypeMedia is the multipart "type" value that routes an upload
+// through the media processing pipeline (ffprobe/ffmpeg) before it is
+// stored.
+const ArtifactTypeMedia = "media"
+
+// GenerateImage validates the uploaded artifact metadata and stream,
+// persists the resulting image record and returns its id.
+func (d *Deployments) GenerateImage(ctx context.Context, multipartUploadMsg *model.MultipartGenerateImageMsg) (string, error) {
+	if multipartUploadMsg == nil || multipartUploadMsg.MetaConstructor == nil {
+		return "", ErrModelMissingInputMetadata
+	}
+
+	exists, err := d.db.ImageByNameAndDeviceTypesExist(ctx,
+		multipartUploadMsg.MetaConstructor.Name,
+		multipartUploadMsg.MetaConstructor.DeviceTypesCompatible)
+	if err != nil {
+		return "", errors.Wrap(err, "Fail to check if artifact is unique")
+	}
+	if exists {
+		return "", ErrModelArtifactNotUnique
+	}
+
+	now := time.Now()
+	image := &model.Image{
+		Id:       uuid.NewV4().String(),
+		Size:     multipartUploadMsg.ArtifactSize,
+		Meta:     multipartUploadMsg.MetaConstructor,
+		Modified: &now,
+	}
+
+	if multipartUploadMsg.Type == ArtifactTypeMedia {
+		media, err := d.processMedia(ctx, multipartUploadMsg)
+		if err != nil {
+			return "", err
+		}
+		image.Media = media
+	}
+
+	if err := d.db.InsertImage(ctx, image); err != nil {
+		return "", errors.Wrap(err, "Fail to store the image metadata")
+	}
+
+	return image.Id, nil
+}
+
+// processMedia runs a media bundle artifact through the configured
+// MediaProcessor, translating its failure modes to the app package's
+// sentinel errors. It is a no-op, returning (nil, nil), when no
+// MediaProcessor has been configured.
+func (d *Deployments) processMedia(ctx context.Context, multipartUploadMsg *model.MultipartGenerateImageMsg) (*model.Media, error) {
+	if d.mediaProcessor == nil {
+		return nil, nil
+	}
+
+	maxSize := d.mediaLocalMaxSize
+	if multipartUploadMsg.Remote {
+		maxSize = d.mediaRemoteMaxSize
+	}
+	if multipartUploadMsg.ArtifactSize > maxSize {
+		return nil, ErrModelMediaTooLarge
+	}
+
+	result, err := d.mediaProcessor.Process(ctx, multipartUploadMsg.ArtifactReader)
+	if err != nil {
+		switch errors.Cause(err) {
+		case processing.ErrUnsupportedCodec:
+			return nil, ErrModelMediaUnsupportedCodec
+		default:
+			return nil, errors.Wrap(ErrModelMediaProcessingFailed, err.Error())
+		}
+	}
+
+	streams := make([]model.MediaStream, 0, len(result.Streams))
+	for _, s := range result.Streams {
+		streams = append(streams, model.MediaStream{
+			Codec:       s.Codec,
+			Duration:    s.Duration,
+			Width:       s.Width,
+			Height:      s.Height,
+			BitrateKbps: s.BitrateKbps,
+		})
+	}
+
+	return &model.Media{
+		Streams:        streams,
+		Thumbnail:      result.Thumbnail,
+		NormalizedSize: result.NormalizedSize,
+	}, nil
+}
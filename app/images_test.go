@@ -0,0 +1,137 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/deployments/app/processing"
+	processing_mocks "github.com/mendersoftware/deployments/app/processing/mocks"
+	"github.com/mendersoftware/deployments/model"
+	store_mocks "github.com/mendersoftware/deployments/store/mocks"
+)
+
+func TestGenerateImageMediaProcessing(t *testing.T) {
+	testCases := map[string]struct {
+		processorResult *processing.Result
+		processorError  error
+		outErr          error
+	}{
+		"ok": {
+			processorResult: &processing.Result{
+				Streams:        []processing.StreamInfo{{Codec: "h264"}},
+				NormalizedSize: 42,
+			},
+		},
+		"ko, unsupported codec": {
+			processorError: processing.ErrUnsupportedCodec,
+			outErr:         ErrModelMediaUnsupportedCodec,
+		},
+		"ko, ffmpeg failure": {
+			processorError: processing.ErrEncodeFailed,
+			outErr:         ErrModelMediaProcessingFailed,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			db := &store_mocks.DataStore{}
+			db.On("ImageByNameAndDeviceTypesExist",
+				mock.MatchedBy(func(context.Context) bool { return true }),
+				mock.AnythingOfType("string"),
+				mock.AnythingOfType("[]string"),
+			).Return(false, nil)
+			db.On("InsertImage",
+				mock.MatchedBy(func(context.Context) bool { return true }),
+				mock.AnythingOfType("*model.Image"),
+			).Return(nil)
+
+			processor := &processing_mocks.MediaProcessor{}
+			processor.On("Process",
+				mock.MatchedBy(func(context.Context) bool { return true }),
+				mock.Anything,
+			).Return(tc.processorResult, tc.processorError)
+
+			d := NewDeployments(db, WithMediaProcessor(processor))
+
+			_, err := d.GenerateImage(context.Background(), &model.MultipartGenerateImageMsg{
+				MetaConstructor: &model.SoftwareImageMetaConstructor{Name: "foo"},
+				ArtifactReader:  strings.NewReader("bundle"),
+				Type:            ArtifactTypeMedia,
+			})
+
+			if tc.outErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, tc.outErr, errors.Cause(err))
+			}
+		})
+	}
+}
+
+func TestGenerateImageMediaTooLarge(t *testing.T) {
+	db := &store_mocks.DataStore{}
+	db.On("ImageByNameAndDeviceTypesExist",
+		mock.MatchedBy(func(context.Context) bool { return true }),
+		mock.AnythingOfType("string"),
+		mock.AnythingOfType("[]string"),
+	).Return(false, nil)
+
+	processor := &processing_mocks.MediaProcessor{}
+
+	d := NewDeployments(db, WithMediaProcessor(processor), WithMediaMaxSize(1, 1))
+
+	_, err := d.GenerateImage(context.Background(), &model.MultipartGenerateImageMsg{
+		MetaConstructor: &model.SoftwareImageMetaConstructor{Name: "foo"},
+		ArtifactSize:    1024,
+		ArtifactReader:  strings.NewReader("bundle"),
+		Type:            ArtifactTypeMedia,
+	})
+
+	assert.Equal(t, ErrModelMediaTooLarge, errors.Cause(err))
+	processor.AssertNotCalled(t, "Process", mock.Anything, mock.Anything)
+}
+
+func TestGenerateImageMediaRemoteMaxSize(t *testing.T) {
+	db := &store_mocks.DataStore{}
+	db.On("ImageByNameAndDeviceTypesExist",
+		mock.MatchedBy(func(context.Context) bool { return true }),
+		mock.AnythingOfType("string"),
+		mock.AnythingOfType("[]string"),
+	).Return(false, nil)
+
+	processor := &processing_mocks.MediaProcessor{}
+
+	// mediaLocalMaxSize is large enough to admit the upload; only
+	// mediaRemoteMaxSize should reject it.
+	d := NewDeployments(db, WithMediaProcessor(processor), WithMediaMaxSize(1024, 1))
+
+	_, err := d.GenerateImage(context.Background(), &model.MultipartGenerateImageMsg{
+		MetaConstructor: &model.SoftwareImageMetaConstructor{Name: "foo"},
+		ArtifactSize:    512,
+		ArtifactReader:  strings.NewReader("bundle"),
+		Type:            ArtifactTypeMedia,
+		Remote:          true,
+	})
+
+	assert.Equal(t, ErrModelMediaTooLarge, errors.Cause(err))
+	processor.AssertNotCalled(t, "Process", mock.Anything, mock.Anything)
+}
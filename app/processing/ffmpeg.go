@@ -0,0 +1,204 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package processing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// Config configures an FFmpegProcessor.
+type Config struct {
+	// FFprobePath and FFmpegPath default to looking the binaries up on
+	// PATH when empty.
+	FFprobePath string
+	FFmpegPath  string
+	// MaxConcurrent bounds how many ffmpeg/ffprobe invocations may run
+	// at once, so a burst of uploads can't fork-bomb the service.
+	// Defaults to 2.
+	MaxConcurrent int
+	// AllowedCodecs restricts which stream codecs are accepted. Empty
+	// means any codec is allowed.
+	AllowedCodecs []string
+}
+
+// FFmpegProcessor is the default MediaProcessor, backed by the ffprobe and
+// ffmpeg binaries.
+type FFmpegProcessor struct {
+	cfg Config
+	sem chan struct{}
+}
+
+// NewFFmpegProcessor creates an FFmpegProcessor from cfg, applying
+// defaults for any zero-valued fields.
+func NewFFmpegProcessor(cfg Config) *FFmpegProcessor {
+	if cfg.FFprobePath == "" {
+		cfg.FFprobePath = "ffprobe"
+	}
+	if cfg.FFmpegPath == "" {
+		cfg.FFmpegPath = "ffmpeg"
+	}
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = 2
+	}
+
+	return &FFmpegProcessor{
+		cfg: cfg,
+		sem: make(chan struct{}, cfg.MaxConcurrent),
+	}
+}
+
+type ffprobeStream struct {
+	CodecName string `json:"codec_name"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Duration  string `json:"duration"`
+	BitRate   string `json:"bit_rate"`
+}
+
+type ffprobeOutput struct {
+	Streams []ffprobeStream `json:"streams"`
+}
+
+// Process implements MediaProcessor.
+func (p *FFmpegProcessor) Process(ctx context.Context, r io.Reader) (*Result, error) {
+	select {
+	case p.sem <- struct{}{}:
+		defer func() { <-p.sem }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	scratch, err := ioutil.TempFile("", "deployments-media-")
+	if err != nil {
+		return nil, ErrProbeFailed
+	}
+	defer os.Remove(scratch.Name())
+	defer scratch.Close()
+
+	if _, err := io.Copy(scratch, r); err != nil {
+		return nil, ErrProbeFailed
+	}
+
+	streams, err := p.probe(ctx, scratch.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.cfg.AllowedCodecs) > 0 {
+		for _, s := range streams {
+			if !containsCodec(p.cfg.AllowedCodecs, s.Codec) {
+				return nil, ErrUnsupportedCodec
+			}
+		}
+	}
+
+	normalizedSize, thumbnail, err := p.encode(ctx, scratch.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Streams:        streams,
+		Thumbnail:      thumbnail,
+		NormalizedSize: normalizedSize,
+	}, nil
+}
+
+func (p *FFmpegProcessor) probe(ctx context.Context, path string) ([]StreamInfo, error) {
+	cmd := exec.CommandContext(ctx, p.cfg.FFprobePath,
+		"-v", "quiet",
+		"-print_format", "json",
+		"-show_streams",
+		path,
+	)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, ErrProbeFailed
+	}
+
+	var parsed ffprobeOutput
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		return nil, ErrProbeFailed
+	}
+
+	streams := make([]StreamInfo, 0, len(parsed.Streams))
+	for _, s := range parsed.Streams {
+		duration, _ := strconv.ParseFloat(s.Duration, 64)
+		bitrate, _ := strconv.ParseInt(s.BitRate, 10, 64)
+		streams = append(streams, StreamInfo{
+			Codec:       s.CodecName,
+			Duration:    duration,
+			Width:       s.Width,
+			Height:      s.Height,
+			BitrateKbps: bitrate / 1000,
+		})
+	}
+
+	return streams, nil
+}
+
+func (p *FFmpegProcessor) encode(ctx context.Context, path string) (int64, []byte, error) {
+	normalized, err := ioutil.TempFile("", "deployments-media-normalized-*.mp4")
+	if err != nil {
+		return 0, nil, ErrEncodeFailed
+	}
+	defer os.Remove(normalized.Name())
+	defer normalized.Close()
+
+	encodeCmd := exec.CommandContext(ctx, p.cfg.FFmpegPath,
+		"-y", "-i", path,
+		"-c:v", "libx264", "-c:a", "aac",
+		"-f", "mp4",
+		normalized.Name(),
+	)
+	if err := encodeCmd.Run(); err != nil {
+		return 0, nil, ErrEncodeFailed
+	}
+
+	info, err := os.Stat(normalized.Name())
+	if err != nil {
+		return 0, nil, ErrEncodeFailed
+	}
+
+	thumbBuf := &bytes.Buffer{}
+	thumbCmd := exec.CommandContext(ctx, p.cfg.FFmpegPath,
+		"-y", "-i", path,
+		"-vframes", "1", "-f", "image2pipe", "-vcodec", "mjpeg",
+		"pipe:1",
+	)
+	thumbCmd.Stdout = thumbBuf
+	if err := thumbCmd.Run(); err != nil {
+		return 0, nil, ErrEncodeFailed
+	}
+
+	return info.Size(), thumbBuf.Bytes(), nil
+}
+
+func containsCodec(codecs []string, codec string) bool {
+	for _, c := range codecs {
+		if c == codec {
+			return true
+		}
+	}
+	return false
+}
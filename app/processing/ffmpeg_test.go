@@ -0,0 +1,60 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package processing
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFFmpegProcessorProcess shells out to the real ffprobe/ffmpeg binaries,
+// skipping if either isn't on PATH: this is the only test in the series
+// that actually exercises FFmpegProcessor rather than the mocked
+// MediaProcessor, so a regression like a malformed ffmpeg invocation (e.g.
+// an output path ffmpeg can't infer a muxer from) is otherwise invisible.
+func TestFFmpegProcessorProcess(t *testing.T) {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		t.Skip("ffmpeg not found on PATH")
+	}
+	ffprobePath, err := exec.LookPath("ffprobe")
+	if err != nil {
+		t.Skip("ffprobe not found on PATH")
+	}
+
+	var input bytes.Buffer
+	gen := exec.Command(ffmpegPath,
+		"-y",
+		"-f", "lavfi", "-i", "testsrc=duration=1:size=64x64:rate=5",
+		"-f", "mp4", "-movflags", "frag_keyframe+empty_moov",
+		"pipe:1",
+	)
+	gen.Stdout = &input
+	require.NoError(t, gen.Run(), "failed to generate test media with ffmpeg")
+
+	p := NewFFmpegProcessor(Config{FFmpegPath: ffmpegPath, FFprobePath: ffprobePath})
+
+	result, err := p.Process(context.Background(), &input)
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Streams)
+	assert.Equal(t, "h264", result.Streams[0].Codec)
+	assert.NotZero(t, result.NormalizedSize)
+	assert.NotEmpty(t, result.Thumbnail)
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package processing normalizes media bundle artifacts (e.g. video/audio
+// payloads embedded in a Mender artifact) by shelling out to ffprobe and
+// ffmpeg.
+package processing
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var (
+	// ErrUnsupportedCodec is returned when a stream's codec is not on
+	// the processor's allow-list.
+	ErrUnsupportedCodec = errors.New("processing: unsupported media codec")
+	// ErrProbeFailed is returned when ffprobe could not inspect the
+	// payload.
+	ErrProbeFailed = errors.New("processing: ffprobe failed to inspect media")
+	// ErrEncodeFailed is returned when ffmpeg could not transcode the
+	// payload.
+	ErrEncodeFailed = errors.New("processing: ffmpeg failed to transcode media")
+)
+
+// StreamInfo describes one audio/video stream as reported by ffprobe.
+type StreamInfo struct {
+	Codec       string
+	Duration    float64
+	Width       int
+	Height      int
+	BitrateKbps int64
+}
+
+// Result is the outcome of processing a media artifact: its streams, a
+// generated thumbnail, and the size it normalized down to.
+type Result struct {
+	Streams        []StreamInfo
+	Thumbnail      []byte
+	NormalizedSize int64
+}
+
+// MediaProcessor inspects and normalizes a media artifact payload.
+type MediaProcessor interface {
+	// Process reads the full payload from r, probes its streams, and
+	// re-encodes it to the processor's canonical codec/container,
+	// returning the outcome. It returns ErrUnsupportedCodec,
+	// ErrProbeFailed or ErrEncodeFailed for the respective failure
+	// modes.
+	Process(ctx context.Context, r io.Reader) (*Result, error)
+}
@@ -0,0 +1,68 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ReapExpiredUploads deletes incomplete tus.io uploads whose expiry has
+// passed, along with their scratch storage.
+func (d *Deployments) ReapExpiredUploads(ctx context.Context) error {
+	ids, err := d.db.FindExpiredUploads(ctx, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "Fail to list expired uploads")
+	}
+
+	for _, id := range ids {
+		if err := d.db.DeleteUpload(ctx, id); err != nil {
+			return errors.Wrapf(err, "Fail to delete expired upload %s", id)
+		}
+	}
+
+	return nil
+}
+
+// UploadJanitor periodically calls ReapExpiredUploads until its context is
+// cancelled.
+type UploadJanitor struct {
+	app      App
+	interval time.Duration
+}
+
+// NewUploadJanitor creates a janitor that reaps expired uploads every
+// interval.
+func NewUploadJanitor(app App, interval time.Duration) *UploadJanitor {
+	return &UploadJanitor{app: app, interval: interval}
+}
+
+// Run blocks, reaping expired uploads every interval, until ctx is
+// cancelled.
+func (j *UploadJanitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = j.app.ReapExpiredUploads(ctx)
+		}
+	}
+}
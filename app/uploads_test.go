@@ -0,0 +1,114 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	app_mocks "github.com/mendersoftware/deployments/app/mocks"
+	store_mocks "github.com/mendersoftware/deployments/store/mocks"
+	h "github.com/mendersoftware/deployments/utils/testing"
+)
+
+func TestReapExpiredUploads(t *testing.T) {
+	testCases := map[string]struct {
+		expiredIDs       []string
+		findErr          error
+		deleteErr        error
+		outErr           string
+		wantDeleteCalled bool
+	}{
+		"ok, nothing expired": {
+			expiredIDs: nil,
+		},
+		"ok, expired uploads are deleted": {
+			expiredIDs:       []string{"upload1", "upload2"},
+			wantDeleteCalled: true,
+		},
+		"ko, listing fails": {
+			findErr: errors.New("db down"),
+			outErr:  "Fail to list expired uploads: db down",
+		},
+		"ko, delete fails": {
+			expiredIDs:       []string{"upload1"},
+			deleteErr:        errors.New("db down"),
+			outErr:           "Fail to delete expired upload upload1: db down",
+			wantDeleteCalled: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			db := &store_mocks.DataStore{}
+			db.On("FindExpiredUploads",
+				h.ContextMatcher(),
+				mock.AnythingOfType("time.Time"),
+			).Return(tc.expiredIDs, tc.findErr)
+			if tc.wantDeleteCalled {
+				db.On("DeleteUpload", h.ContextMatcher(), mock.AnythingOfType("string")).
+					Return(tc.deleteErr)
+			}
+
+			d := NewDeployments(db)
+
+			err := d.ReapExpiredUploads(context.Background())
+
+			if tc.outErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.outErr)
+			}
+
+			if !tc.wantDeleteCalled {
+				db.AssertNotCalled(t, "DeleteUpload", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestUploadJanitorRun(t *testing.T) {
+	t.Run("reaps on every tick until the context is cancelled", func(t *testing.T) {
+		a := &app_mocks.App{}
+		reaped := make(chan struct{}, 2)
+		a.On("ReapExpiredUploads", h.ContextMatcher()).
+			Run(func(args mock.Arguments) { reaped <- struct{}{} }).
+			Return(nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		j := NewUploadJanitor(a, time.Millisecond)
+
+		done := make(chan struct{})
+		go func() {
+			j.Run(ctx)
+			close(done)
+		}()
+
+		<-reaped
+		<-reaped
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("UploadJanitor.Run did not return after its context was cancelled")
+		}
+	})
+}
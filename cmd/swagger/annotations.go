@@ -0,0 +1,284 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// paramAnno is one parsed "@Param" line.
+type paramAnno struct {
+	Name        string
+	In          string
+	Type        string
+	Required    bool
+	Description string
+}
+
+// responseAnno is one parsed "@Success" or "@Failure" line.
+type responseAnno struct {
+	Status      string
+	SchemaRef   string
+	Description string
+}
+
+// parsedOp is the annotation content of a single handler's doc comment,
+// before it is turned into an Operation.
+type parsedOp struct {
+	Path        string
+	Method      string
+	Summary     string
+	Description string
+	Tags        []string
+	Accept      []string
+	Responses   []responseAnno
+	Params      []paramAnno
+}
+
+var routerLineRe = regexp.MustCompile(`^(\S+)\s+\[(\w+)\]$`)
+var responseLineRe = regexp.MustCompile(`^(\d+)\s*(?:\{(\w+)\}\s*(\S+))?\s*(?:"(.*)")?$`)
+var paramLineRe = regexp.MustCompile(`^(\S+)\s+(\S+)\s+(\S+)\s+(true|false)\s+"(.*)"$`)
+
+// parseAnnotations turns a Go doc comment containing "@"-prefixed lines
+// into a parsedOp. Lines without a recognised "@Tag" are ignored, so plain
+// prose in the same comment (the Go doc comment itself) is left alone.
+func parseAnnotations(doc string) (*parsedOp, error) {
+	op := &parsedOp{}
+
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+
+		fields := strings.SplitN(line[1:], " ", 2)
+		tag := fields[0]
+		rest := ""
+		if len(fields) > 1 {
+			rest = strings.TrimSpace(fields[1])
+		}
+
+		switch tag {
+		case "Summary":
+			op.Summary = rest
+		case "Description":
+			op.Description = rest
+		case "Tags":
+			op.Tags = strings.Split(rest, ",")
+		case "Accept":
+			op.Accept = strings.Split(rest, ",")
+		case "Produce":
+			// Recorded for completeness; every handler here produces
+			// application/json or an empty body, so it isn't used to
+			// shape the spec beyond documentation.
+		case "Param":
+			m := paramLineRe.FindStringSubmatch(rest)
+			if m == nil {
+				return nil, fmt.Errorf("malformed @Param line: %q", rest)
+			}
+			op.Params = append(op.Params, paramAnno{
+				Name:        m[1],
+				In:          m[2],
+				Type:        m[3],
+				Required:    m[4] == "true",
+				Description: m[5],
+			})
+		case "Success", "Failure":
+			m := responseLineRe.FindStringSubmatch(rest)
+			if m == nil {
+				return nil, fmt.Errorf("malformed @%s line: %q", tag, rest)
+			}
+			op.Responses = append(op.Responses, responseAnno{
+				Status:      m[1],
+				SchemaRef:   m[3],
+				Description: m[4],
+			})
+		case "Router":
+			m := routerLineRe.FindStringSubmatch(rest)
+			if m == nil {
+				return nil, fmt.Errorf("malformed @Router line: %q", rest)
+			}
+			op.Path = m[1]
+			op.Method = strings.ToLower(m[2])
+		}
+	}
+
+	if op.Path == "" {
+		return nil, fmt.Errorf("doc comment has no @Router line")
+	}
+
+	return op, nil
+}
+
+// extractOperations parses every .go file directly under dir and returns
+// the parsedOp for each exported *DeploymentsApiHandlers method whose doc
+// comment carries a "@Router" annotation.
+func extractOperations(dir string) ([]*parsedOp, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []*parsedOp
+	for _, pkg := range pkgs {
+		files := make([]string, 0, len(pkg.Files))
+		for name := range pkg.Files {
+			files = append(files, name)
+		}
+		sort.Strings(files)
+
+		for _, name := range files {
+			file := pkg.Files[name]
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Doc == nil || !isHandlerReceiver(fn) {
+					continue
+				}
+				if !hasRouterLine(fn.Doc.Text()) {
+					continue
+				}
+
+				op, err := parseAnnotations(fn.Doc.Text())
+				if err != nil {
+					return nil, fmt.Errorf("%s: func %s: %w", name, fn.Name.Name, err)
+				}
+				ops = append(ops, op)
+			}
+		}
+	}
+
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].Path != ops[j].Path {
+			return ops[i].Path < ops[j].Path
+		}
+		return ops[i].Method < ops[j].Method
+	})
+
+	return ops, nil
+}
+
+// hasRouterLine reports whether doc has a line that is itself a "@Router"
+// annotation, as opposed to merely mentioning "@Router" in prose.
+func hasRouterLine(doc string) bool {
+	for _, line := range strings.Split(doc, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "@Router") {
+			return true
+		}
+	}
+	return false
+}
+
+func isHandlerReceiver(fn *ast.FuncDecl) bool {
+	if fn.Recv == nil || len(fn.Recv.List) != 1 {
+		return false
+	}
+	star, ok := fn.Recv.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := star.X.(*ast.Ident)
+	return ok && ident.Name == "DeploymentsApiHandlers"
+}
+
+// buildOperation turns a parsedOp into the Operation embedded in the spec,
+// splitting its @Param lines between header/path/query Parameters and a
+// multipart/form-data RequestBody, and resolving every "pkg.Type" schema
+// reference against resolved (populated by resolveSchemas).
+func buildOperation(p *parsedOp, resolved map[string]Schema) (Operation, error) {
+	op := Operation{
+		Summary:     p.Summary,
+		Description: p.Description,
+		Tags:        p.Tags,
+		Responses:   map[string]Response{},
+	}
+
+	var formFields []paramAnno
+	for _, param := range p.Params {
+		if param.In == "formData" {
+			formFields = append(formFields, param)
+			continue
+		}
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        param.Name,
+			In:          param.In,
+			Required:    param.Required,
+			Description: param.Description,
+			Schema:      Schema{Type: openAPIType(param.Type)},
+		})
+	}
+
+	if len(formFields) > 0 {
+		properties := make(map[string]Schema, len(formFields))
+		for _, field := range formFields {
+			properties[field.Name] = Schema{Type: openAPIType(field.Type)}
+		}
+		contentType := "multipart/form-data"
+		if len(p.Accept) > 0 {
+			contentType = p.Accept[0]
+		}
+		op.RequestBody = &RequestBody{
+			Content: map[string]MediaType{
+				contentType: {Schema: Schema{Type: "object", Properties: properties}},
+			},
+		}
+	} else if len(p.Accept) > 0 && p.Accept[0] != "" && p.Accept[0] != "json" {
+		op.RequestBody = &RequestBody{
+			Content: map[string]MediaType{
+				p.Accept[0]: {Schema: Schema{Type: "string", Format: "binary"}},
+			},
+		}
+	}
+
+	for _, resp := range p.Responses {
+		response := Response{Description: resp.Description}
+		if resp.SchemaRef != "" {
+			if _, ok := resolved[resp.SchemaRef]; !ok {
+				return Operation{}, fmt.Errorf("unresolved schema reference %q", resp.SchemaRef)
+			}
+			response.Content = map[string]MediaType{
+				"application/json": {Schema: Schema{Ref: "#/components/schemas/" + refName(resp.SchemaRef)}},
+			}
+		}
+		op.Responses[resp.Status] = response
+	}
+
+	return op, nil
+}
+
+// refName turns a "pkg.Type" schema reference into the component name used
+// under components/schemas, since "." isn't valid there.
+func refName(pkgType string) string {
+	return strings.ReplaceAll(pkgType, ".", "")
+}
+
+// openAPIType maps the handful of parameter types used in this service's
+// annotations to their OpenAPI primitive.
+func openAPIType(t string) string {
+	switch t {
+	case "integer", "int", "int64":
+		return "integer"
+	case "boolean", "bool":
+		return "boolean"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAnnotations(t *testing.T) {
+	doc := `GenerateImage accepts a multipart/form-data artifact upload.
+
+@Summary Upload an artifact
+@Description Accepts an artifact as a single multipart/form-data request and stores it.
+@Tags artifacts
+@Accept multipart/form-data
+@Param name formData string true "Artifact name"
+@Success 201 "Created"
+@Failure 400 {object} view.ValidationErrorResponse "Malformed request"
+@Router /artifacts/generate [post]
+`
+
+	op, err := parseAnnotations(doc)
+	assert.NoError(t, err)
+	assert.Equal(t, "/artifacts/generate", op.Path)
+	assert.Equal(t, "post", op.Method)
+	assert.Equal(t, "Upload an artifact", op.Summary)
+	assert.Equal(t, []string{"artifacts"}, op.Tags)
+	assert.Equal(t, []string{"multipart/form-data"}, op.Accept)
+
+	if assert.Len(t, op.Params, 1) {
+		assert.Equal(t, paramAnno{
+			Name:        "name",
+			In:          "formData",
+			Type:        "string",
+			Required:    true,
+			Description: "Artifact name",
+		}, op.Params[0])
+	}
+
+	if assert.Len(t, op.Responses, 2) {
+		assert.Equal(t, responseAnno{Status: "201", Description: "Created"}, op.Responses[0])
+		assert.Equal(t, responseAnno{
+			Status:      "400",
+			SchemaRef:   "view.ValidationErrorResponse",
+			Description: "Malformed request",
+		}, op.Responses[1])
+	}
+}
+
+func TestParseAnnotationsMissingRouter(t *testing.T) {
+	_, err := parseAnnotations("@Summary Does something\n")
+	assert.Error(t, err)
+}
+
+func TestParseAnnotationsMalformedParam(t *testing.T) {
+	_, err := parseAnnotations("@Param name formData string true\n@Router /x [get]\n")
+	assert.Error(t, err)
+}
+
+func TestRefName(t *testing.T) {
+	assert.Equal(t, "viewValidationErrorResponse", refName("view.ValidationErrorResponse"))
+}
+
+func TestOpenAPIType(t *testing.T) {
+	assert.Equal(t, "integer", openAPIType("integer"))
+	assert.Equal(t, "boolean", openAPIType("bool"))
+	assert.Equal(t, "string", openAPIType("string"))
+}
@@ -0,0 +1,57 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+const docsPackageTemplate = `// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Code generated by "make swagger" from the @-annotations in api/http. DO NOT EDIT.
+
+// Package docs embeds the OpenAPI spec served at /api/0.0.1/openapi.yaml and
+// /api/0.0.1/docs.
+package docs
+
+// OpenAPISpec is the full OpenAPI 3.0 document, as YAML.
+const OpenAPISpec = ` + "`%s`" + `
+`
+
+// writeDocsPackage renders docsPackageTemplate with specYAML embedded as a
+// raw Go string literal and writes the result to path. specYAML is assumed
+// not to contain a backtick, which holds for every schema this generator
+// currently emits.
+func writeDocsPackage(path, specYAML string) error {
+	if strings.Contains(specYAML, "`") {
+		return fmt.Errorf("generated spec contains a backtick, cannot embed as a raw string literal")
+	}
+	source := fmt.Sprintf(docsPackageTemplate, specYAML)
+	return ioutil.WriteFile(path, []byte(source), 0o644)
+}
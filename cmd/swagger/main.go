@@ -0,0 +1,109 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	httpDir  = "api/http"
+	modelDir = "model"
+	viewDir  = "utils/restutil/view"
+	outDir   = "docs"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "swagger:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	ops, err := extractOperations(httpDir)
+	if err != nil {
+		return fmt.Errorf("extracting operations from %s: %w", httpDir, err)
+	}
+	if len(ops) == 0 {
+		return fmt.Errorf("no @Router-annotated handlers found under %s", httpDir)
+	}
+
+	refs := map[string]bool{}
+	for _, op := range ops {
+		for _, resp := range op.Responses {
+			if resp.SchemaRef != "" {
+				refs[resp.SchemaRef] = true
+			}
+		}
+	}
+
+	resolved, err := resolveSchemas(refs, map[string]string{
+		"model": modelDir,
+		"view":  viewDir,
+	})
+	if err != nil {
+		return fmt.Errorf("resolving response schemas: %w", err)
+	}
+
+	spec := &OpenAPISpec{
+		OpenAPI: "3.0.0",
+		Info: Info{
+			Title:   "Deployments API",
+			Version: "0.0.1",
+		},
+		Paths:      map[string]PathItem{},
+		Components: Components{Schemas: map[string]Schema{}},
+	}
+
+	for ref, schema := range resolved {
+		spec.Components.Schemas[refName(ref)] = schema
+	}
+
+	for _, p := range ops {
+		operation, err := buildOperation(p, resolved)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", p.Method, p.Path, err)
+		}
+
+		item, ok := spec.Paths[p.Path]
+		if !ok {
+			item = PathItem{}
+		}
+		item[p.Method] = operation
+		spec.Paths[p.Path] = item
+	}
+
+	specYAML, err := yaml.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("marshaling spec: %w", err)
+	}
+
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return err
+	}
+
+	specPath := filepath.Join(outDir, "openapi.yaml")
+	if err := ioutil.WriteFile(specPath, specYAML, 0o644); err != nil {
+		return err
+	}
+
+	return writeDocsPackage(filepath.Join(outDir, "docs.go"), string(specYAML))
+}
@@ -0,0 +1,218 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// resolveSchemas parses every "pkg.Type" reference collected from @Success
+// / @Failure annotations into an OpenAPI schema, by locating the struct's
+// declaration under the source directory packageDirs[pkg] maps to.
+//
+// Only the field shapes actually used by this service's response bodies
+// are supported: strings, integers, booleans, slices of those, and one
+// level of nested named structs in the same set of packages.
+func resolveSchemas(refs map[string]bool, packageDirs map[string]string) (map[string]Schema, error) {
+	fset := token.NewFileSet()
+	structsByPkg := map[string]map[string]*ast.StructType{}
+
+	for pkg, dir := range packageDirs {
+		pkgs, err := parser.ParseDir(fset, dir, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		structs := map[string]*ast.StructType{}
+		for _, astPkg := range pkgs {
+			for _, file := range astPkg.Files {
+				for _, decl := range file.Decls {
+					gen, ok := decl.(*ast.GenDecl)
+					if !ok || gen.Tok != token.TYPE {
+						continue
+					}
+					for _, spec := range gen.Specs {
+						ts, ok := spec.(*ast.TypeSpec)
+						if !ok {
+							continue
+						}
+						if st, ok := ts.Type.(*ast.StructType); ok {
+							structs[ts.Name.Name] = st
+						}
+					}
+				}
+			}
+		}
+		structsByPkg[pkg] = structs
+	}
+
+	resolved := map[string]Schema{}
+	for ref := range refs {
+		schema, err := resolveStruct(ref, structsByPkg, 0)
+		if err != nil {
+			return nil, err
+		}
+		resolved[ref] = schema
+	}
+
+	return resolved, nil
+}
+
+func resolveStruct(ref string, structsByPkg map[string]map[string]*ast.StructType, depth int) (Schema, error) {
+	pkg, name, err := splitRef(ref)
+	if err != nil {
+		return Schema{}, err
+	}
+
+	structs, ok := structsByPkg[pkg]
+	if !ok {
+		return Schema{}, fmt.Errorf("no known source directory for package %q (ref %q)", pkg, ref)
+	}
+	st, ok := structs[name]
+	if !ok {
+		return Schema{}, fmt.Errorf("struct %q not found in package %q", name, pkg)
+	}
+
+	properties := map[string]Schema{}
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 0 {
+			// Embedded field; this service's response bodies don't use
+			// embedding, so skip rather than guess.
+			continue
+		}
+
+		jsonName, omit := jsonFieldName(field)
+		if omit {
+			continue
+		}
+
+		fieldSchema, err := fieldTypeSchema(field.Type, pkg, structsByPkg, depth)
+		if err != nil {
+			return Schema{}, fmt.Errorf("%s.%s: %w", ref, field.Names[0].Name, err)
+		}
+		properties[jsonName] = fieldSchema
+	}
+
+	return Schema{Type: "object", Properties: properties}, nil
+}
+
+func fieldTypeSchema(expr ast.Expr, pkg string, structsByPkg map[string]map[string]*ast.StructType, depth int) (Schema, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return Schema{Type: "string"}, nil
+		case "bool":
+			return Schema{Type: "boolean"}, nil
+		case "int", "int32", "int64", "uint", "uint32", "uint64":
+			return Schema{Type: "integer"}, nil
+		default:
+			if depth >= 1 {
+				// Avoid unbounded recursion through self-referential or
+				// deeply nested model types; one level is enough to
+				// describe every response body this service emits today.
+				return Schema{Type: "object"}, nil
+			}
+			nested, err := resolveStruct(pkg+"."+t.Name, structsByPkg, depth+1)
+			if err != nil {
+				return Schema{}, err
+			}
+			return nested, nil
+		}
+	case *ast.StarExpr:
+		return fieldTypeSchema(t.X, pkg, structsByPkg, depth)
+	case *ast.ArrayType:
+		items, err := fieldTypeSchema(t.Elt, pkg, structsByPkg, depth)
+		if err != nil {
+			return Schema{}, err
+		}
+		return Schema{Type: "array", Items: &items}, nil
+	case *ast.SelectorExpr:
+		otherPkg, ok := t.X.(*ast.Ident)
+		if ok && depth < 1 {
+			if _, known := structsByPkg[otherPkg.Name]; known {
+				nested, err := resolveStruct(otherPkg.Name+"."+t.Sel.Name, structsByPkg, depth+1)
+				if err == nil {
+					return nested, nil
+				}
+			}
+		}
+		// A type from a package this generator doesn't scan (e.g.
+		// time.Time); model it as an opaque string since none of this
+		// service's response bodies rely on its internal shape.
+		return Schema{Type: "string"}, nil
+	default:
+		return Schema{Type: "object"}, nil
+	}
+}
+
+// jsonFieldName returns the JSON name a struct field is encoded under,
+// honouring a `json:"..."` tag, and whether it is tagged `json:"-"`.
+func jsonFieldName(field *ast.Field) (name string, omit bool) {
+	name = field.Names[0].Name
+
+	if field.Tag == nil {
+		return name, false
+	}
+
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return name, false
+	}
+
+	jsonTag := extractTag(tag, "json")
+	if jsonTag == "" {
+		return name, false
+	}
+
+	parts := strings.Split(jsonTag, ",")
+	if parts[0] == "-" {
+		return "", true
+	}
+	if parts[0] != "" {
+		name = parts[0]
+	}
+
+	return name, false
+}
+
+// extractTag pulls the value of key out of a raw (unquoted) struct tag
+// string, without pulling in reflect.StructTag (this runs over AST nodes,
+// not live types).
+func extractTag(tag, key string) string {
+	prefix := key + `:"`
+	idx := strings.Index(tag, prefix)
+	if idx == -1 {
+		return ""
+	}
+	rest := tag[idx+len(prefix):]
+	end := strings.Index(rest, `"`)
+	if end == -1 {
+		return ""
+	}
+	return rest[:end]
+}
+
+func splitRef(ref string) (pkg, name string, err error) {
+	idx := strings.LastIndex(ref, ".")
+	if idx == -1 {
+		return "", "", fmt.Errorf("schema reference %q is not of the form pkg.Type", ref)
+	}
+	return ref[:idx], ref[idx+1:], nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Command swagger scans the api/http package for @-annotated handler doc
+// comments and emits an OpenAPI 3.0 spec to docs/openapi.yaml, plus a
+// docs package embedding it for the self-hosted docs endpoint. Run it via
+// `make swagger` after changing a handler's annotations or the models it
+// references.
+package main
+
+// OpenAPISpec is the root of the generated document.
+type OpenAPISpec struct {
+	OpenAPI    string              `yaml:"openapi"`
+	Info       Info                `yaml:"info"`
+	Paths      map[string]PathItem `yaml:"paths"`
+	Components Components          `yaml:"components"`
+}
+
+// Info is the OpenAPI "info" object.
+type Info struct {
+	Title   string `yaml:"title"`
+	Version string `yaml:"version"`
+}
+
+// PathItem maps an HTTP method (lowercase) to the Operation served at it.
+type PathItem map[string]Operation
+
+// Operation is one "@Router"-annotated handler.
+type Operation struct {
+	Summary     string              `yaml:"summary,omitempty"`
+	Description string              `yaml:"description,omitempty"`
+	Tags        []string            `yaml:"tags,omitempty"`
+	Parameters  []Parameter         `yaml:"parameters,omitempty"`
+	RequestBody *RequestBody        `yaml:"requestBody,omitempty"`
+	Responses   map[string]Response `yaml:"responses"`
+}
+
+// Parameter is an OpenAPI path/header/query parameter.
+type Parameter struct {
+	Name        string `yaml:"name"`
+	In          string `yaml:"in"`
+	Required    bool   `yaml:"required,omitempty"`
+	Description string `yaml:"description,omitempty"`
+	Schema      Schema `yaml:"schema"`
+}
+
+// RequestBody is an OpenAPI request body, used for multipart/form-data and
+// octet-stream handlers whose inputs aren't headers or path parameters.
+type RequestBody struct {
+	Content map[string]MediaType `yaml:"content"`
+}
+
+// MediaType pairs a content type with the schema of its body.
+type MediaType struct {
+	Schema Schema `yaml:"schema"`
+}
+
+// Response is one status code's worth of documentation for an Operation.
+type Response struct {
+	Description string               `yaml:"description"`
+	Content     map[string]MediaType `yaml:"content,omitempty"`
+}
+
+// Schema is an OpenAPI schema object. Only the subset needed to describe
+// this service's handlers and models is implemented.
+type Schema struct {
+	Type       string            `yaml:"type,omitempty"`
+	Format     string            `yaml:"format,omitempty"`
+	Items      *Schema           `yaml:"items,omitempty"`
+	Properties map[string]Schema `yaml:"properties,omitempty"`
+	Ref        string            `yaml:"$ref,omitempty"`
+}
+
+// Components holds the reusable schemas referenced by Operations via
+// Schema.Ref.
+type Components struct {
+	Schemas map[string]Schema `yaml:"schemas,omitempty"`
+}
@@ -0,0 +1,45 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+// DefaultMaxNameLength is used for ArtifactUploadConstraints.MaxNameLength
+// when configuration does not override it.
+const DefaultMaxNameLength = 4096
+
+// ArtifactUploadConstraints is the set of rules a multipart artifact
+// upload must satisfy, loaded from configuration and shared by the REST
+// handler and app.GenerateImage so both enforce exactly the same rules.
+type ArtifactUploadConstraints struct {
+	// MaxSize is the largest accepted artifact size, in bytes. Zero
+	// means unlimited.
+	MaxSize int64
+	// AllowedTypes restricts the accepted multipart "type" field. Empty
+	// means any type is allowed.
+	AllowedTypes []string
+	// MaxNameLength bounds the length of the "name" field. Zero means
+	// DefaultMaxNameLength.
+	MaxNameLength int
+	// ForbiddenNameChars lists characters that may not appear in the
+	// "name" field.
+	ForbiddenNameChars string
+}
+
+// FieldError reports a single constraint violation, as one entry of a
+// ValidationErrorResponse.
+type FieldError struct {
+	Name    string `json:"name"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,61 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"io"
+	"time"
+)
+
+// SoftwareImageMetaConstructor holds the user supplied metadata submitted
+// alongside an artifact upload.
+type SoftwareImageMetaConstructor struct {
+	Name                  string   `json:"name" bson:"name"`
+	Description           string   `json:"description,omitempty" bson:"description,omitempty"`
+	DeviceTypesCompatible []string `json:"device_types_compatible" bson:"device_types_compatible"`
+}
+
+// Image represents a Mender artifact stored by the deployments service.
+type Image struct {
+	Id       string                        `json:"id" bson:"_id"`
+	Size     int64                         `json:"size" bson:"size"`
+	Meta     *SoftwareImageMetaConstructor `json:"-" bson:"meta"`
+	Modified *time.Time                    `json:"modified" bson:"modified"`
+
+	// Media holds the result of the processing pipeline when the
+	// uploaded artifact was identified as a media bundle.
+	Media *Media `json:"media,omitempty" bson:"media,omitempty"`
+}
+
+// MultipartGenerateImageMsg is the parsed representation of the multipart/
+// form-data body accepted by DeploymentsApiHandlers.GenerateImage.
+type MultipartGenerateImageMsg struct {
+	// MetaConstructor holds the user supplied metadata fields.
+	MetaConstructor *SoftwareImageMetaConstructor
+	// ArtifactSize is the declared size of the artifact in bytes.
+	ArtifactSize int64
+	// ArtifactReader streams the raw artifact bytes.
+	ArtifactReader io.Reader
+	// Type selects the artifacttype.Processor used to parse/build the
+	// artifact, e.g. "single_file".
+	Type string
+	// Args carries processor specific, free-form arguments.
+	Args string
+	// Remote marks an upload as having arrived over the tus.io resumable
+	// endpoint (mobile/edge, WAN), as opposed to the single-shot
+	// multipart endpoint (local network); it selects which of
+	// media_local_max_size/media_remote_max_size bounds a media bundle.
+	Remote bool
+}
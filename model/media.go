@@ -0,0 +1,34 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+// MediaStream describes one audio/video stream found by ffprobe inside a
+// media artifact.
+type MediaStream struct {
+	Codec       string  `json:"codec" bson:"codec"`
+	Duration    float64 `json:"duration_seconds" bson:"duration_seconds"`
+	Width       int     `json:"width,omitempty" bson:"width,omitempty"`
+	Height      int     `json:"height,omitempty" bson:"height,omitempty"`
+	BitrateKbps int64   `json:"bitrate_kbps" bson:"bitrate_kbps"`
+}
+
+// Media holds the outcome of running a media artifact through the
+// processing pipeline: its inspected streams, a generated thumbnail and
+// the size it normalized down to.
+type Media struct {
+	Streams        []MediaStream `json:"streams" bson:"streams"`
+	Thumbnail      []byte        `json:"thumbnail,omitempty" bson:"thumbnail,omitempty"`
+	NormalizedSize int64         `json:"normalized_size" bson:"normalized_size"`
+}
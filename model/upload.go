@@ -0,0 +1,44 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import "time"
+
+// Upload tracks the state of a single tus.io resumable artifact upload
+// between the Creation (POST) and the final PATCH that completes it.
+type Upload struct {
+	Id string `bson:"_id"`
+
+	// Size is the total upload length declared by the client in the
+	// Upload-Length header.
+	Size int64 `bson:"size"`
+	// Offset is the number of bytes received so far.
+	Offset int64 `bson:"offset"`
+
+	// Meta holds the tus Upload-Metadata key/value pairs (name,
+	// description, device_types_compatible, type, args).
+	Meta *SoftwareImageMetaConstructor `bson:"meta"`
+	Type string `bson:"type"`
+	Args string `bson:"args"`
+
+	CreatedAt time.Time `bson:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// IsComplete reports whether every declared byte of the upload has been
+// received.
+func (u *Upload) IsComplete() bool {
+	return u.Offset >= u.Size
+}
@@ -0,0 +1,34 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package store
+
+import (
+	"context"
+
+	"github.com/mendersoftware/deployments/model"
+)
+
+// DataStore is the persistence interface consumed by the app layer and the
+// API handlers that need to read or write image/artifact state directly.
+type DataStore interface {
+	UploadStore
+
+	// ImageByNameAndDeviceTypesExist reports whether an image with the
+	// given name is already compatible with any of the given device
+	// types.
+	ImageByNameAndDeviceTypesExist(ctx context.Context, name string, deviceTypes []string) (bool, error)
+	// InsertImage persists a new image record.
+	InsertImage(ctx context.Context, image *model.Image) error
+}
@@ -0,0 +1,272 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package mongo implements store.DataStore against a MongoDB deployment.
+// Resumable upload bytes are kept in a GridFS bucket rather than in the
+// upload's own document, since a single BSON document is capped at 16MiB
+// and TusMaxSize allows uploads far larger than that.
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/mendersoftware/deployments/model"
+)
+
+const (
+	collectionImages  = "images"
+	collectionUploads = "uploads"
+	gridFSBucketName  = "upload_chunks"
+)
+
+// DataStoreMongo is a store.DataStore backed by MongoDB. Each resumable
+// upload's chunks are stored as separate GridFS files tagged with the
+// upload id and offset they belong to, so completing an upload never
+// requires holding a single open write stream across the several HTTP
+// requests that built it up.
+type DataStoreMongo struct {
+	db     *mongo.Database
+	bucket *gridfs.Bucket
+}
+
+// NewDataStoreMongo creates a DataStoreMongo backed by the given database
+// name on client.
+func NewDataStoreMongo(client *mongo.Client, dbName string) (*DataStoreMongo, error) {
+	db := client.Database(dbName)
+
+	bucket, err := gridfs.NewBucket(db, options.GridFSBucket().SetName(gridFSBucketName))
+	if err != nil {
+		return nil, errors.Wrap(err, "mongo: failed to open the upload chunk bucket")
+	}
+
+	return &DataStoreMongo{
+		db:     db,
+		bucket: bucket,
+	}, nil
+}
+
+// ImageByNameAndDeviceTypesExist implements store.DataStore.
+func (ds *DataStoreMongo) ImageByNameAndDeviceTypesExist(ctx context.Context, name string, deviceTypes []string) (bool, error) {
+	filter := bson.M{
+		"meta.name": name,
+	}
+	if len(deviceTypes) > 0 {
+		filter["meta.device_types_compatible"] = bson.M{"$in": deviceTypes}
+	}
+
+	count, err := ds.db.Collection(collectionImages).CountDocuments(ctx, filter)
+	if err != nil {
+		return false, errors.Wrap(err, "mongo: failed to check for an existing image")
+	}
+
+	return count > 0, nil
+}
+
+// InsertImage implements store.DataStore.
+func (ds *DataStoreMongo) InsertImage(ctx context.Context, image *model.Image) error {
+	_, err := ds.db.Collection(collectionImages).InsertOne(ctx, image)
+	return errors.Wrap(err, "mongo: failed to insert image")
+}
+
+// CreateUpload implements store.UploadStore.
+func (ds *DataStoreMongo) CreateUpload(ctx context.Context, upload *model.Upload) error {
+	_, err := ds.db.Collection(collectionUploads).InsertOne(ctx, upload)
+	return errors.Wrap(err, "mongo: failed to insert upload")
+}
+
+// FindUpload implements store.UploadStore.
+func (ds *DataStoreMongo) FindUpload(ctx context.Context, id string) (*model.Upload, error) {
+	var upload model.Upload
+	err := ds.db.Collection(collectionUploads).FindOne(ctx, bson.M{"_id": id}).Decode(&upload)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "mongo: failed to find upload")
+	}
+
+	return &upload, nil
+}
+
+// chunkMetadata tags a GridFS file as belonging to one resumable upload, at
+// the offset the chunk started at.
+type chunkMetadata struct {
+	UploadID string `bson:"upload_id"`
+	Offset   int64  `bson:"offset"`
+}
+
+// AppendUploadChunk implements store.UploadStore. The offset update is
+// conditioned on the upload's stored offset still matching offset, so two
+// chunks racing to append at the same position can't both succeed.
+func (ds *DataStoreMongo) AppendUploadChunk(ctx context.Context, id string, offset int64, chunk io.Reader) (int64, error) {
+	filename := fmt.Sprintf("%s.chunk.%020d", id, offset)
+	uploadStream, err := ds.bucket.OpenUploadStream(filename,
+		options.GridFSUpload().SetMetadata(chunkMetadata{UploadID: id, Offset: offset}))
+	if err != nil {
+		return 0, errors.Wrap(err, "mongo: failed to open chunk upload stream")
+	}
+
+	written, err := io.Copy(uploadStream, chunk)
+	if err != nil {
+		uploadStream.Close()
+		ds.bucket.Delete(uploadStream.FileID)
+		return 0, errors.Wrap(err, "mongo: failed to write upload chunk")
+	}
+	if err := uploadStream.Close(); err != nil {
+		return 0, errors.Wrap(err, "mongo: failed to finalize upload chunk")
+	}
+
+	newOffset := offset + written
+	res, err := ds.db.Collection(collectionUploads).UpdateOne(ctx,
+		bson.M{"_id": id, "offset": offset},
+		bson.M{"$set": bson.M{"offset": newOffset}},
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "mongo: failed to record the new upload offset")
+	}
+	if res.MatchedCount == 0 {
+		ds.bucket.Delete(uploadStream.FileID)
+		return 0, errors.Errorf("mongo: upload %s offset does not match the expected %d", id, offset)
+	}
+
+	return newOffset, nil
+}
+
+// OpenUpload implements store.UploadStore.
+func (ds *DataStoreMongo) OpenUpload(ctx context.Context, id string) (io.ReadCloser, error) {
+	fileIDs, err := ds.chunkFileIDs(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chunkSeriesReader{bucket: ds.bucket, fileIDs: fileIDs}, nil
+}
+
+// DeleteUpload implements store.UploadStore.
+func (ds *DataStoreMongo) DeleteUpload(ctx context.Context, id string) error {
+	fileIDs, err := ds.chunkFileIDs(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, fileID := range fileIDs {
+		if err := ds.bucket.Delete(fileID); err != nil {
+			return errors.Wrap(err, "mongo: failed to delete upload chunk")
+		}
+	}
+
+	_, err = ds.db.Collection(collectionUploads).DeleteOne(ctx, bson.M{"_id": id})
+	return errors.Wrap(err, "mongo: failed to delete upload")
+}
+
+// FindExpiredUploads implements store.UploadStore.
+func (ds *DataStoreMongo) FindExpiredUploads(ctx context.Context, before time.Time) ([]string, error) {
+	cur, err := ds.db.Collection(collectionUploads).Find(ctx, bson.M{
+		"expires_at": bson.M{"$lt": before},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "mongo: failed to list expired uploads")
+	}
+	defer cur.Close(ctx)
+
+	var ids []string
+	for cur.Next(ctx) {
+		var upload model.Upload
+		if err := cur.Decode(&upload); err != nil {
+			return nil, errors.Wrap(err, "mongo: failed to decode expired upload")
+		}
+		ids = append(ids, upload.Id)
+	}
+
+	return ids, errors.Wrap(cur.Err(), "mongo: failed to list expired uploads")
+}
+
+// chunkFileIDs returns the GridFS file ids of an upload's chunks, in the
+// offset order they were written.
+func (ds *DataStoreMongo) chunkFileIDs(ctx context.Context, id string) ([]primitive.ObjectID, error) {
+	cur, err := ds.db.Collection(gridFSBucketName+".files").Find(ctx,
+		bson.M{"metadata.upload_id": id},
+		options.Find().SetSort(bson.D{{Key: "metadata.offset", Value: 1}}),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "mongo: failed to list upload chunks")
+	}
+	defer cur.Close(ctx)
+
+	var fileIDs []primitive.ObjectID
+	for cur.Next(ctx) {
+		var file struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cur.Decode(&file); err != nil {
+			return nil, errors.Wrap(err, "mongo: failed to decode upload chunk")
+		}
+		fileIDs = append(fileIDs, file.ID)
+	}
+
+	return fileIDs, errors.Wrap(cur.Err(), "mongo: failed to list upload chunks")
+}
+
+// chunkSeriesReader reads a resumable upload's chunks back in offset order,
+// opening each chunk's GridFS download stream only once the previous one is
+// exhausted.
+type chunkSeriesReader struct {
+	bucket  *gridfs.Bucket
+	fileIDs []primitive.ObjectID
+	current *gridfs.DownloadStream
+}
+
+func (r *chunkSeriesReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.fileIDs) == 0 {
+				return 0, io.EOF
+			}
+			stream, err := r.bucket.OpenDownloadStream(r.fileIDs[0])
+			if err != nil {
+				return 0, errors.Wrap(err, "mongo: failed to open upload chunk")
+			}
+			r.fileIDs = r.fileIDs[1:]
+			r.current = stream
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *chunkSeriesReader) Close() error {
+	if r.current != nil {
+		return r.current.Close()
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package store
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/mendersoftware/deployments/model"
+)
+
+// UploadStore persists the state of in-progress tus.io resumable uploads,
+// both the small bookkeeping record (size, offset, metadata) and the raw
+// bytes received so far.
+type UploadStore interface {
+	// CreateUpload records a freshly initiated upload.
+	CreateUpload(ctx context.Context, upload *model.Upload) error
+	// FindUpload looks up an upload by id. It returns nil, nil if no
+	// such upload exists.
+	FindUpload(ctx context.Context, id string) (*model.Upload, error)
+	// AppendUploadChunk appends chunk to the upload's scratch storage at
+	// the given offset and returns the resulting total offset. It
+	// returns an error if offset does not match the upload's current
+	// offset.
+	AppendUploadChunk(ctx context.Context, id string, offset int64, chunk io.Reader) (int64, error)
+	// OpenUpload returns a reader over the bytes received so far for a
+	// completed upload, for handing off to app.GenerateImage.
+	OpenUpload(ctx context.Context, id string) (io.ReadCloser, error)
+	// DeleteUpload removes the upload's bookkeeping record and scratch
+	// storage.
+	DeleteUpload(ctx context.Context, id string) error
+	// FindExpiredUploads returns the ids of incomplete uploads whose
+	// expiry is before the given time, for the janitor to reap.
+	FindExpiredUploads(ctx context.Context, before time.Time) ([]string, error)
+}
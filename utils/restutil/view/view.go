@@ -0,0 +1,84 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package view holds the shared response rendering helpers used by the
+// REST API handlers.
+package view
+
+import (
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"github.com/mendersoftware/deployments/model"
+)
+
+// ErrorResponse is the body returned for every non-2xx response.
+type ErrorResponse struct {
+	Err string `json:"error"`
+}
+
+// ValidationErrorResponse is the body returned when a request fails one or
+// more model.ArtifactUploadConstraints checks.
+type ValidationErrorResponse struct {
+	Err    string             `json:"error"`
+	Fields []model.FieldError `json:"fields"`
+}
+
+// RESTView renders the common success/error response shapes shared by all
+// API handlers.
+type RESTView struct{}
+
+// RenderError writes err as an ErrorResponse with the given status code.
+func (v *RESTView) RenderError(w rest.ResponseWriter, r *rest.Request, err error, status int) {
+	w.WriteHeader(status)
+	_ = w.WriteJson(ErrorResponse{Err: err.Error()})
+}
+
+// RenderInternalError writes err as an ErrorResponse with a generic message
+// and a 500 status, so internal details are never leaked to the client.
+func (v *RESTView) RenderInternalError(w rest.ResponseWriter, r *rest.Request, err error) {
+	w.WriteHeader(http.StatusInternalServerError)
+	_ = w.WriteJson(ErrorResponse{Err: "internal error"})
+}
+
+// UnsupportedTypeResponse is the body returned when a request's "type"
+// field names a type with no registered artifacttype.Processor, so
+// clients can discover what is actually supported.
+type UnsupportedTypeResponse struct {
+	Err            string   `json:"error"`
+	SupportedTypes []string `json:"supported_types"`
+}
+
+// RenderUnsupportedType writes supportedTypes as an UnsupportedTypeResponse
+// with a 400 status.
+func (v *RESTView) RenderUnsupportedType(w rest.ResponseWriter, r *rest.Request, supportedTypes []string) {
+	w.WriteHeader(http.StatusBadRequest)
+	_ = w.WriteJson(UnsupportedTypeResponse{Err: "unsupported type", SupportedTypes: supportedTypes})
+}
+
+// RenderValidationError writes fields as a ValidationErrorResponse with the
+// given status code, for constraint violations collected from a single
+// request.
+func (v *RESTView) RenderValidationError(w rest.ResponseWriter, r *rest.Request, fields []model.FieldError, status int) {
+	w.WriteHeader(status)
+	_ = w.WriteJson(ValidationErrorResponse{Err: "validation_failed", Fields: fields})
+}
+
+// RenderSuccessPost writes a 201 Created response with a Location header
+// pointing at the newly created resource.
+func (v *RESTView) RenderSuccessPost(w rest.ResponseWriter, r *rest.Request, id string) {
+	w.Header().Set("Location", r.URL.Path+"/"+id)
+	w.WriteHeader(http.StatusCreated)
+}
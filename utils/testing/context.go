@@ -0,0 +1,29 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package testing provides small shared helpers used by the handler and
+// app layer test suites.
+package testing
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// ContextMatcher returns a mock.Anything-style matcher for context.Context
+// arguments, for use with testify's mock.On(...).
+func ContextMatcher() interface{} {
+	return mock.MatchedBy(func(_ context.Context) bool { return true })
+}
@@ -0,0 +1,66 @@
+// Copyright 2019 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package testing
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+)
+
+// Part describes a single part of a multipart/form-data body built by
+// MakeMultipartRequest: either a plain form field (FieldValue) or a file
+// part (ContentType + ImageData).
+type Part struct {
+	FieldName   string
+	FieldValue  string
+	ContentType string
+	ImageData   []byte
+}
+
+// MakeMultipartRequest builds an *http.Request with a multipart/form-data
+// body assembled from parts. When contentType is empty, no Content-Type
+// header is set at all, so the handler under test sees a request with no
+// declared media type.
+func MakeMultipartRequest(method, url, contentType string, parts []Part) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	for _, p := range parts {
+		if p.ImageData != nil {
+			header := make(map[string][]string)
+			header["Content-Disposition"] = []string{
+				`form-data; name="` + p.FieldName + `"; filename="artifact"`,
+			}
+			if p.ContentType != "" {
+				header["Content-Type"] = []string{p.ContentType}
+			}
+			part, _ := writer.CreatePart(header)
+			_, _ = part.Write(p.ImageData)
+		} else {
+			_ = writer.WriteField(p.FieldName, p.FieldValue)
+		}
+	}
+	_ = writer.Close()
+
+	req, _ := http.NewRequest(method, url, body)
+	if contentType == "multipart/form-data" {
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+	} else if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return req
+}